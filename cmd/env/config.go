@@ -4,12 +4,27 @@ import (
 	"fmt"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/sirupsen/logrus"
+	"weather-service/internal/weather"
 )
 
+// maxCoordinatePrecision is the most decimals a float64 coordinate can hold.
+const maxCoordinatePrecision = 16
+
 type AppConfig struct {
-	OpenMateoURL string `envconfig:"OPEN_MATEO_URL"`
-	DynamoDBName string `envconfig:"DYNAMODB_TABLE"`
-	TTL          int    `envconfig:"TTL_MINUTES"`
+	OpenMateoURL        string `envconfig:"OPEN_MATEO_URL"`
+	OpenMateoHourlyURL  string `envconfig:"OPEN_MATEO_HOURLY_URL"`
+	OpenMateoArchiveURL string `envconfig:"OPEN_MATEO_ARCHIVE_URL"`
+	DynamoDBName        string `envconfig:"DYNAMODB_TABLE"`
+	GazetteerFile       string `envconfig:"GAZETTEER_FILE"`
+	TTL                 int    `envconfig:"TTL_MINUTES"`
+	ListenAddr          string `envconfig:"LISTEN_ADDR" default:":8080"`
+	CacheBackend        string `envconfig:"CACHE_BACKEND" default:"dynamodb"`
+	// CoordinatePrecision is the number of decimals lat/lon are rounded to.
+	CoordinatePrecision int `envconfig:"COORDINATE_PRECISION" default:"2"`
+	// CoordinateGrid snaps lat/lon to multiples of it, in degrees. 0 disables it.
+	CoordinateGrid float64 `envconfig:"COORDINATE_GRID"`
+	// ForecastDays is the forecast horizon, today included.
+	ForecastDays int `envconfig:"FORECAST_DAYS" default:"7"`
 }
 
 func LoadAppConfig() (AppConfig, error) {
@@ -19,5 +34,13 @@ func LoadAppConfig() (AppConfig, error) {
 		return AppConfig{}, fmt.Errorf("failed to parse configuration from environment: %w", err)
 	}
 
+	if config.ForecastDays < 1 || config.ForecastDays > weather.MaxForecastDays {
+		return AppConfig{}, fmt.Errorf("FORECAST_DAYS must be between 1 and %d, got %d", weather.MaxForecastDays, config.ForecastDays)
+	}
+
+	if config.CoordinatePrecision < 1 || config.CoordinatePrecision > maxCoordinatePrecision {
+		return AppConfig{}, fmt.Errorf("COORDINATE_PRECISION must be between 1 and %d, got %d", maxCoordinatePrecision, config.CoordinatePrecision)
+	}
+
 	return config, nil
 }
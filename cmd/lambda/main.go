@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"weather-service/cmd/env"
 	"weather-service/internal/cache"
+	"weather-service/internal/gazetteer"
 	"weather-service/internal/handler"
 	"weather-service/internal/weather"
 )
@@ -26,7 +27,8 @@ func main() {
 
 	// Initializing weather client
 	httpClient := &http.Client{}
-	weatherClient := weather.NewOpenMateoClient(httpClient, appConfig.OpenMateoURL)
+	weatherClient := weather.NewOpenMateoClient(httpClient, appConfig.OpenMateoURL, appConfig.OpenMateoHourlyURL)
+	weatherClient.ForecastDays = appConfig.ForecastDays
 
 	// Loading AWS config
 	cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion("eu-west-1"))
@@ -40,9 +42,27 @@ func main() {
 	// Initializing Cache
 	weatherCache := cache.NewDynamoDBCache(dynamoDBClient, appConfig.DynamoDBName, appConfig.TTL)
 
+	// Initializing gazetteer
+	places, err := gazetteer.New(appConfig.GazetteerFile)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to load gazetteer")
+	}
+
 	// Initializing handler
 	service := handler.NewWeatherService(weatherClient, weatherCache)
+	service.Geocoder = places
+	// nearby bundled places, until Open-Meteo reports the location's timezone
+	service.Timezones = places
+	service.CoordinatePrecision = appConfig.CoordinatePrecision
+	service.CoordinateGrid = appConfig.CoordinateGrid
+	service.ForecastDays = appConfig.ForecastDays
+	if appConfig.OpenMateoArchiveURL != "" {
+		service.ArchiveClient = weather.NewOpenMateoArchiveClient(httpClient, appConfig.OpenMateoArchiveURL)
+		// the forecast serves the recent days the archive does not have yet
+		weatherClient.PastDays = weather.ArchiveLagDays
+		service.PastDays = weather.ArchiveLagDays
+	}
 
 	logrus.Info("Starting Weather api Lambda")
-	lambda.Start(service.HandleRequest)
+	lambda.Start(service.HandleHTTPRequest)
 }
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"weather-service/cmd/env"
+	"weather-service/internal/cache"
+	"weather-service/internal/gazetteer"
+	"weather-service/internal/handler"
+	"weather-service/internal/weather"
+)
+
+const shutdownTimeout = 10 * time.Second
+
+func main() {
+
+	logrus.SetFormatter(&logrus.JSONFormatter{})
+	logrus.SetLevel(logrus.InfoLevel)
+
+	//Loading env vars
+	appConfig, err := env.LoadAppConfig()
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	// Initializing weather client
+	httpClient := &http.Client{}
+	weatherClient := weather.NewOpenMateoClient(httpClient, appConfig.OpenMateoURL, appConfig.OpenMateoHourlyURL)
+	weatherClient.ForecastDays = appConfig.ForecastDays
+
+	// Initializing Cache
+	weatherCache, err := newCache(appConfig)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to create cache")
+	}
+
+	// Initializing gazetteer
+	places, err := gazetteer.New(appConfig.GazetteerFile)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to load gazetteer")
+	}
+
+	// Initializing handler
+	service := handler.NewWeatherService(weatherClient, weatherCache)
+	service.Geocoder = places
+	// nearby bundled places, until Open-Meteo reports the location's timezone
+	service.Timezones = places
+	service.CoordinatePrecision = appConfig.CoordinatePrecision
+	service.CoordinateGrid = appConfig.CoordinateGrid
+	service.ForecastDays = appConfig.ForecastDays
+	if appConfig.OpenMateoArchiveURL != "" {
+		service.ArchiveClient = weather.NewOpenMateoArchiveClient(httpClient, appConfig.OpenMateoArchiveURL)
+		// the forecast serves the recent days the archive does not have yet
+		weatherClient.PastDays = weather.ArchiveLagDays
+		service.PastDays = weather.ArchiveLagDays
+	}
+
+	mux := http.NewServeMux()
+	for path, methods := range handler.Routes {
+		for _, method := range methods {
+			mux.Handle(method+" "+path, service)
+		}
+	}
+	// problem responses rather than the mux's plain text 404 and 405
+	mux.HandleFunc("/", handler.Unrouted)
+
+	server := &http.Server{
+		Addr:    appConfig.ListenAddr,
+		Handler: mux,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	go func() {
+		logrus.WithField("addr", appConfig.ListenAddr).Info("Starting Weather api server")
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.WithError(err).Fatal("Weather api server failed")
+		}
+	}()
+
+	<-ctx.Done()
+	logrus.Info("Shutting down Weather api server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logrus.WithError(err).Error("Failed to shut down Weather api server gracefully")
+	}
+}
+
+func newCache(appConfig env.AppConfig) (handler.Cache, error) {
+	switch appConfig.CacheBackend {
+	case "memory":
+		return cache.NewMemoryCache(appConfig.TTL), nil
+	case "dynamodb":
+		// Loading AWS config
+		cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion("eu-west-1"))
+		if err != nil {
+			return nil, err
+		}
+		return cache.NewDynamoDBCache(dynamodb.NewFromConfig(cfg), appConfig.DynamoDBName, appConfig.TTL), nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", appConfig.CacheBackend)
+	}
+}
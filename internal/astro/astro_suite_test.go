@@ -0,0 +1,13 @@
+package astro_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestAstro(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Astro Suite")
+}
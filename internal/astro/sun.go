@@ -0,0 +1,84 @@
+// Package astro computes sun times from a location and a date, for when the
+// weather provider does not report them.
+package astro
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// j2000 is the Julian date of 2000-01-01 12:00 UTC.
+	j2000 = 2451545.0
+	// unixEpoch is the Julian date of 1970-01-01 00:00 UTC.
+	unixEpoch = 2440587.5
+	// horizon is the altitude of the sun's centre at sunrise and sunset,
+	// accounting for refraction and the sun's radius.
+	horizon = -0.833
+	// obliquity is the tilt of the Earth's axis.
+	obliquity = 23.4397
+)
+
+// Sun holds the sun times of a day, in UTC. Sunrise and Sunset are zero on
+// days the sun does not rise or set, which have 24 hours or no daylight.
+type Sun struct {
+	Sunrise  time.Time
+	Sunset   time.Time
+	Noon     time.Time
+	Daylight time.Duration
+}
+
+// SunTimes computes the sun times on the calendar day of date at lat/lon,
+// in decimal degrees with east longitudes positive, with the sunrise
+// equation. They are accurate to about a minute outside polar regions.
+func SunTimes(date time.Time, lat, lon float64) Sun {
+	midnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	n := math.Round(julianDate(midnight) + 0.5 - j2000 + 0.0008)
+
+	meanNoon := n - lon/360
+	anomaly := normalizeDegrees(357.5291 + 0.98560028*meanNoon)
+	center := 1.9148*sin(anomaly) + 0.0200*sin(2*anomaly) + 0.0003*sin(3*anomaly)
+	longitude := normalizeDegrees(anomaly + center + 180 + 102.9372)
+	transit := j2000 + meanNoon + 0.0053*sin(anomaly) - 0.0069*sin(2*longitude)
+
+	declination := math.Asin(sin(longitude) * sin(obliquity))
+	cosHourAngle := (sin(horizon) - sin(lat)*math.Sin(declination)) / (cos(lat) * math.Cos(declination))
+
+	sun := Sun{Noon: fromJulianDate(transit)}
+	switch {
+	case cosHourAngle < -1:
+		sun.Daylight = 24 * time.Hour
+	case cosHourAngle > 1:
+	default:
+		hourAngle := math.Acos(cosHourAngle) * 180 / math.Pi
+		sun.Sunrise = fromJulianDate(transit - hourAngle/360)
+		sun.Sunset = fromJulianDate(transit + hourAngle/360)
+		sun.Daylight = sun.Sunset.Sub(sun.Sunrise)
+	}
+	return sun
+}
+
+func julianDate(t time.Time) float64 {
+	return float64(t.Unix())/86400 + unixEpoch
+}
+
+// fromJulianDate converts a Julian date to a UTC time, rounded to the second.
+func fromJulianDate(jd float64) time.Time {
+	return time.Unix(int64(math.Round((jd-unixEpoch)*86400)), 0).UTC()
+}
+
+func normalizeDegrees(d float64) float64 {
+	d = math.Mod(d, 360)
+	if d < 0 {
+		d += 360
+	}
+	return d
+}
+
+func sin(degrees float64) float64 {
+	return math.Sin(degrees * math.Pi / 180)
+}
+
+func cos(degrees float64) float64 {
+	return math.Cos(degrees * math.Pi / 180)
+}
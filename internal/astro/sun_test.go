@@ -0,0 +1,41 @@
+package astro_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+	"weather-service/internal/astro"
+)
+
+var _ = Describe("SunTimes", func() {
+	sofia, _ := time.LoadLocation("Europe/Sofia")
+
+	It("should compute sunrise, sunset and solar noon", func() {
+		sun := astro.SunTimes(time.Date(2025, 6, 21, 0, 0, 0, 0, sofia), 42.70, 23.32)
+		Expect(sun.Sunrise.In(sofia)).To(BeTemporally("~", time.Date(2025, 6, 21, 5, 49, 0, 0, sofia), 3*time.Minute))
+		Expect(sun.Sunset.In(sofia)).To(BeTemporally("~", time.Date(2025, 6, 21, 21, 9, 0, 0, sofia), 3*time.Minute))
+		Expect(sun.Noon.In(sofia)).To(BeTemporally("~", time.Date(2025, 6, 21, 13, 28, 0, 0, sofia), 3*time.Minute))
+		Expect(sun.Daylight).To(Equal(sun.Sunset.Sub(sun.Sunrise)))
+	})
+
+	It("should handle the southern hemisphere", func() {
+		sydney, _ := time.LoadLocation("Australia/Sydney")
+		sun := astro.SunTimes(time.Date(2025, 6, 21, 0, 0, 0, 0, sydney), -33.87, 151.21)
+		Expect(sun.Sunrise.In(sydney)).To(BeTemporally("~", time.Date(2025, 6, 21, 7, 0, 0, 0, sydney), 3*time.Minute))
+		Expect(sun.Sunset.In(sydney)).To(BeTemporally("~", time.Date(2025, 6, 21, 16, 54, 0, 0, sydney), 3*time.Minute))
+	})
+
+	It("should report polar day", func() {
+		sun := astro.SunTimes(time.Date(2025, 6, 21, 0, 0, 0, 0, time.UTC), 69.65, 18.96)
+		Expect(sun.Sunrise.IsZero()).To(BeTrue())
+		Expect(sun.Sunset.IsZero()).To(BeTrue())
+		Expect(sun.Daylight).To(Equal(24 * time.Hour))
+	})
+
+	It("should report polar night", func() {
+		sun := astro.SunTimes(time.Date(2025, 12, 21, 0, 0, 0, 0, time.UTC), 69.65, 18.96)
+		Expect(sun.Sunrise.IsZero()).To(BeTrue())
+		Expect(sun.Daylight).To(BeZero())
+		Expect(sun.Noon.IsZero()).To(BeFalse())
+	})
+})
@@ -38,7 +38,57 @@ func (c *DynamoDBCache) Put(key string, weather *handler.CachedWeather) error {
 	weather.Key = key
 	weather.TTL = time.Now().Add(time.Duration(c.ttlMinutes) * time.Minute).Unix()
 
-	item, err := attributevalue.MarshalMap(weather)
+	return c.putItem(weather)
+}
+
+// PutPermanent stores weather without a TTL attribute, so DynamoDB never
+// expires it.
+func (c *DynamoDBCache) PutPermanent(key string, weather *handler.CachedWeather) error {
+	weather.Key = key
+	weather.TTL = 0
+
+	return c.putItem(weather)
+}
+
+func (c *DynamoDBCache) Get(key string) (*handler.CachedWeather, error) {
+	var data handler.CachedWeather
+	found, err := c.getItem(key, &data)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	// check for expire, if so ignore
+	if data.TTL != 0 && data.TTL < time.Now().Unix() {
+		return nil, nil
+	}
+
+	return &data, nil
+}
+
+func (c *DynamoDBCache) PutHourly(key string, weather *handler.CachedHourlyWeather) error {
+	weather.Key = key
+	weather.TTL = time.Now().Add(time.Duration(c.ttlMinutes) * time.Minute).Unix()
+
+	return c.putItem(weather)
+}
+
+func (c *DynamoDBCache) GetHourly(key string) (*handler.CachedHourlyWeather, error) {
+	var data handler.CachedHourlyWeather
+	found, err := c.getItem(key, &data)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	// check for expire, if so ignore
+	if data.TTL < time.Now().Unix() {
+		return nil, nil
+	}
+
+	return &data, nil
+}
+
+func (c *DynamoDBCache) putItem(in interface{}) error {
+	item, err := attributevalue.MarshalMap(in)
 	if err != nil {
 		return err
 	}
@@ -51,13 +101,14 @@ func (c *DynamoDBCache) Put(key string, weather *handler.CachedWeather) error {
 	return err
 }
 
-func (c *DynamoDBCache) Get(key string) (*handler.CachedWeather, error) {
+// getItem loads the item stored under key into out and reports whether it was found.
+func (c *DynamoDBCache) getItem(key string, out interface{}) (bool, error) {
 	logrus.WithFields(logrus.Fields{
 		"key": key,
 	}).Info("Going to get a weather from cache")
 
 	if key == "" {
-		return nil, fmt.Errorf("empty key provided")
+		return false, fmt.Errorf("empty key provided")
 	}
 
 	resp, err := c.client.GetItem(context.TODO(), &dynamodb.GetItemInput{
@@ -68,24 +119,18 @@ func (c *DynamoDBCache) Get(key string) (*handler.CachedWeather, error) {
 	})
 	if err != nil {
 		logging.LogError(fmt.Errorf("error while getting weather from cache: %w", err), map[string]interface{}{"key": key})
-		return nil, err
+		return false, err
 	}
 
 	if resp.Item == nil {
-		return nil, nil // not found, and we will make a request if nothing was found so we do not need an error
+		return false, nil // not found, and we will make a request if nothing was found so we do not need an error
 	}
 
-	var data handler.CachedWeather
-	err = attributevalue.UnmarshalMap(resp.Item, &data)
+	err = attributevalue.UnmarshalMap(resp.Item, out)
 	if err != nil {
 		logging.LogError(fmt.Errorf("error while unmarshaling weather from cache: %w", err), map[string]interface{}{"key": key})
-		return nil, err
+		return false, err
 	}
 
-	// check for expire, if so ignore
-	if data.TTL < time.Now().Unix() {
-		return nil, nil
-	}
-
-	return &data, nil
+	return true, nil
 }
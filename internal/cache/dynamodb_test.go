@@ -1,6 +1,7 @@
 package cache_test
 
 import (
+	"context"
 	"errors"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
@@ -15,6 +16,8 @@ import (
 )
 
 var _ = Describe("Dynamodb", mockutil.Mockable(func(helper *mockutil.Helper) {
+	uvIndex, rainProbability := 7.8, 40.0
+
 	var (
 		mockDynamoDBClient *mocks.MockDynamoDBClient
 		dynamoDBClient     *cache.DynamoDBCache
@@ -32,8 +35,8 @@ var _ = Describe("Dynamodb", mockutil.Mockable(func(helper *mockutil.Helper) {
 				item = handler.CachedWeather{
 					Key:      "42.0_23.0_2025-07-10",
 					TempMax:  30.5,
-					UVIndex:  7.8,
-					RainProb: 40.0,
+					UVIndex:  &uvIndex,
+					RainProb: &rainProbability,
 					TTL:      123621653216,
 				}
 				av, err := attributevalue.MarshalMap(item)
@@ -54,6 +57,22 @@ var _ = Describe("Dynamodb", mockutil.Mockable(func(helper *mockutil.Helper) {
 			})
 		})
 
+		When("item has no TTL", func() {
+			BeforeEach(func() {
+				av, err := attributevalue.MarshalMap(handler.CachedWeather{Key: "42.0_23.0_2020-07-10", TempMax: 30.5})
+				Expect(err).To(BeNil())
+				mockDynamoDBClient.EXPECT().GetItem(gomock.Any(), gomock.Any()).Return(&dynamodb.GetItemOutput{
+					Item: av,
+				}, nil).Times(1)
+			})
+
+			It("should never expire", func() {
+				res, err := dynamoDBClient.Get("42.0_23.0_2020-07-10")
+				Expect(err).To(BeNil())
+				Expect(res.TempMax).To(Equal(30.5))
+			})
+		})
+
 		When("dynamodb returns an error", func() {
 			BeforeEach(func() {
 				mockDynamoDBClient.EXPECT().GetItem(gomock.Any(), gomock.Any()).Return(nil, errors.New("error")).Times(1)
@@ -96,8 +115,8 @@ var _ = Describe("Dynamodb", mockutil.Mockable(func(helper *mockutil.Helper) {
 	Context("PutItem", func() {
 		cachedWeather := &handler.CachedWeather{
 			TempMax:  30.5,
-			UVIndex:  7.8,
-			RainProb: 40.0,
+			UVIndex:  &uvIndex,
+			RainProb: &rainProbability,
 		}
 		When("everything works", func() {
 			BeforeEach(func() {
@@ -123,4 +142,79 @@ var _ = Describe("Dynamodb", mockutil.Mockable(func(helper *mockutil.Helper) {
 		})
 	})
 
+	Context("PutPermanent", func() {
+		When("everything works", func() {
+			var input *dynamodb.PutItemInput
+			BeforeEach(func() {
+				mockDynamoDBClient.EXPECT().PutItem(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, in *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+					input = in
+					return nil, nil
+				}).Times(1)
+			})
+
+			It("should store the item without TTL", func() {
+				err := dynamoDBClient.PutPermanent("43.0_23.9_2020-07-10", &handler.CachedWeather{TempMax: 30.5})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(input.Item).To(HaveKey("Key"))
+				Expect(input.Item).ToNot(HaveKey("TTL"))
+			})
+		})
+	})
+
+	Context("GetHourly", func() {
+		When("everything works", func() {
+			var item handler.CachedHourlyWeather
+			BeforeEach(func() {
+				item = handler.CachedHourlyWeather{
+					Key: "42.0_23.0_2025-07-10_hourly",
+					Hours: []handler.CachedHour{
+						{Time: "2025-07-10T00:00", Temp: 18.5, RainProb: 5, WindSpeed: 4.2, CloudCover: 20},
+					},
+					TTL: 123621653216,
+				}
+				av, err := attributevalue.MarshalMap(item)
+				Expect(err).To(BeNil())
+				mockDynamoDBClient.EXPECT().GetItem(gomock.Any(), gomock.Any()).Return(&dynamodb.GetItemOutput{
+					Item: av,
+				}, nil).Times(1)
+			})
+
+			It("should return the item", func() {
+				res, err := dynamoDBClient.GetHourly("42.0_23.0_2025-07-10_hourly")
+				Expect(err).To(BeNil())
+				Expect(res.Key).To(Equal(item.Key))
+				Expect(res.Hours).To(Equal(item.Hours))
+			})
+		})
+
+		When("dynamodb returns nil item", func() {
+			BeforeEach(func() {
+				mockDynamoDBClient.EXPECT().GetItem(gomock.Any(), gomock.Any()).Return(&dynamodb.GetItemOutput{
+					Item: nil,
+				}, nil).Times(1)
+			})
+
+			It("should return nil", func() {
+				res, err := dynamoDBClient.GetHourly("42.0_23.0_2025-07-10_hourly")
+				Expect(err).To(BeNil())
+				Expect(res).To(BeNil())
+			})
+		})
+	})
+
+	Context("PutHourly", func() {
+		When("everything works", func() {
+			BeforeEach(func() {
+				mockDynamoDBClient.EXPECT().PutItem(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+			})
+
+			It("should return no error", func() {
+				err := dynamoDBClient.PutHourly("42.0_23.0_2025-07-10_hourly", &handler.CachedHourlyWeather{
+					Hours: []handler.CachedHour{{Time: "2025-07-10T00:00", Temp: 18.5}},
+				})
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+	})
+
 }))
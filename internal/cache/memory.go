@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"weather-service/internal/handler"
+)
+
+// DefaultMaxMemoryItems bounds how many daily and how many hourly items a
+// MemoryCache holds.
+const DefaultMaxMemoryItems = 100000
+
+// MemoryCache is an in-process cache, used when running the service locally
+// without DynamoDB. Expired items are deleted when they are read or when the
+// cache is full, and arbitrary items are evicted when it is still full then,
+// as items put permanently never expire.
+type MemoryCache struct {
+	mu         sync.RWMutex
+	items      map[string]handler.CachedWeather
+	hourly     map[string]handler.CachedHourlyWeather
+	ttlMinutes int
+	// MaxItems bounds how many daily and how many hourly items are held, 0
+	// leaves them unbounded.
+	MaxItems int
+}
+
+func NewMemoryCache(ttl int) *MemoryCache {
+	return &MemoryCache{
+		items:      make(map[string]handler.CachedWeather),
+		hourly:     make(map[string]handler.CachedHourlyWeather),
+		ttlMinutes: ttl,
+		MaxItems:   DefaultMaxMemoryItems,
+	}
+}
+
+func (c *MemoryCache) Put(key string, weather *handler.CachedWeather) error {
+	if key == "" {
+		return fmt.Errorf("empty key provided")
+	}
+
+	weather.Key = key
+	weather.TTL = time.Now().Add(time.Duration(c.ttlMinutes) * time.Minute).Unix()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	makeRoom(c.items, key, c.MaxItems, func(item handler.CachedWeather) int64 { return item.TTL })
+	c.items[key] = *weather
+
+	return nil
+}
+
+// PutPermanent stores weather without expiry.
+func (c *MemoryCache) PutPermanent(key string, weather *handler.CachedWeather) error {
+	if key == "" {
+		return fmt.Errorf("empty key provided")
+	}
+
+	weather.Key = key
+	weather.TTL = 0
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	makeRoom(c.items, key, c.MaxItems, func(item handler.CachedWeather) int64 { return item.TTL })
+	c.items[key] = *weather
+
+	return nil
+}
+
+func (c *MemoryCache) Get(key string) (*handler.CachedWeather, error) {
+	if key == "" {
+		return nil, fmt.Errorf("empty key provided")
+	}
+
+	c.mu.RLock()
+	data, ok := c.items[key]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, nil
+	}
+
+	// check for expire, if so delete it
+	if data.TTL != 0 && data.TTL < time.Now().Unix() {
+		c.mu.Lock()
+		if current, ok := c.items[key]; ok && current.TTL == data.TTL {
+			delete(c.items, key)
+		}
+		c.mu.Unlock()
+		return nil, nil
+	}
+
+	return &data, nil
+}
+
+func (c *MemoryCache) PutHourly(key string, weather *handler.CachedHourlyWeather) error {
+	if key == "" {
+		return fmt.Errorf("empty key provided")
+	}
+
+	weather.Key = key
+	weather.TTL = time.Now().Add(time.Duration(c.ttlMinutes) * time.Minute).Unix()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	makeRoom(c.hourly, key, c.MaxItems, func(item handler.CachedHourlyWeather) int64 { return item.TTL })
+	c.hourly[key] = *weather
+
+	return nil
+}
+
+func (c *MemoryCache) GetHourly(key string) (*handler.CachedHourlyWeather, error) {
+	if key == "" {
+		return nil, fmt.Errorf("empty key provided")
+	}
+
+	c.mu.RLock()
+	data, ok := c.hourly[key]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, nil
+	}
+
+	// check for expire, if so delete it
+	if data.TTL < time.Now().Unix() {
+		c.mu.Lock()
+		if current, ok := c.hourly[key]; ok && current.TTL == data.TTL {
+			delete(c.hourly, key)
+		}
+		c.mu.Unlock()
+		return nil, nil
+	}
+
+	return &data, nil
+}
+
+// makeRoom makes sure items has room for key, deleting the expired items
+// when it is full and then arbitrary ones while it still is. ttl returns the
+// expiry of an item, 0 for items that never expire.
+func makeRoom[T any](items map[string]T, key string, max int, ttl func(T) int64) {
+	if _, ok := items[key]; ok || max <= 0 || len(items) < max {
+		return
+	}
+
+	now := time.Now().Unix()
+	for k, item := range items {
+		if expiry := ttl(item); expiry != 0 && expiry < now {
+			delete(items, k)
+		}
+	}
+	for k := range items {
+		if len(items) < max {
+			return
+		}
+		delete(items, k)
+	}
+}
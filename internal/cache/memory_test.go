@@ -0,0 +1,126 @@
+package cache_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"weather-service/internal/cache"
+	"weather-service/internal/handler"
+)
+
+var _ = Describe("Memory", func() {
+	uvIndex, rainProbability := 7.8, 40.0
+	var memoryCache *cache.MemoryCache
+
+	BeforeEach(func() {
+		memoryCache = cache.NewMemoryCache(10)
+	})
+
+	Context("Get", func() {
+		When("item was put before", func() {
+			BeforeEach(func() {
+				err := memoryCache.Put("42.0_23.0_2025-07-10", &handler.CachedWeather{
+					TempMax:  30.5,
+					UVIndex:  &uvIndex,
+					RainProb: &rainProbability,
+				})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should return the item", func() {
+				res, err := memoryCache.Get("42.0_23.0_2025-07-10")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res.Key).To(Equal("42.0_23.0_2025-07-10"))
+				Expect(res.TempMax).To(Equal(30.5))
+				Expect(*res.UVIndex).To(Equal(7.8))
+				Expect(*res.RainProb).To(Equal(40.0))
+			})
+		})
+
+		When("item is expired", func() {
+			BeforeEach(func() {
+				memoryCache = cache.NewMemoryCache(-1)
+				Expect(memoryCache.Put("42.0_23.0_2025-07-10", &handler.CachedWeather{TempMax: 30.5})).To(Succeed())
+			})
+
+			It("should return nil", func() {
+				res, err := memoryCache.Get("42.0_23.0_2025-07-10")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(BeNil())
+			})
+		})
+
+		When("item was put permanently", func() {
+			BeforeEach(func() {
+				memoryCache = cache.NewMemoryCache(-1)
+				Expect(memoryCache.PutPermanent("42.0_23.0_2020-07-10", &handler.CachedWeather{TempMax: 30.5})).To(Succeed())
+			})
+
+			It("should never expire", func() {
+				res, err := memoryCache.Get("42.0_23.0_2020-07-10")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res.TempMax).To(Equal(30.5))
+				Expect(res.TTL).To(BeZero())
+			})
+		})
+
+		When("item is missing", func() {
+			It("should return nil", func() {
+				res, err := memoryCache.Get("42.0_23.0_2025-07-10")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(BeNil())
+			})
+		})
+
+		When("no key is provided", func() {
+			It("should return error", func() {
+				_, err := memoryCache.Get("")
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("empty key provided"))
+			})
+		})
+	})
+
+	Context("MaxItems", func() {
+		When("the cache is full of expired items", func() {
+			BeforeEach(func() {
+				memoryCache = cache.NewMemoryCache(-1)
+				memoryCache.MaxItems = 2
+				Expect(memoryCache.Put("42.0_23.0_2025-07-10", &handler.CachedWeather{TempMax: 30.5})).To(Succeed())
+				Expect(memoryCache.Put("42.0_23.0_2025-07-11", &handler.CachedWeather{TempMax: 31.5})).To(Succeed())
+				Expect(memoryCache.PutPermanent("42.0_23.0_2020-07-10", &handler.CachedWeather{TempMax: 28.5})).To(Succeed())
+				Expect(memoryCache.PutPermanent("42.0_23.0_2020-07-11", &handler.CachedWeather{TempMax: 29.5})).To(Succeed())
+			})
+
+			It("should delete them to make room", func() {
+				for _, key := range []string{"42.0_23.0_2020-07-10", "42.0_23.0_2020-07-11"} {
+					res, err := memoryCache.Get(key)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(res).ToNot(BeNil())
+				}
+			})
+		})
+
+		When("the cache is full of items that do not expire", func() {
+			BeforeEach(func() {
+				memoryCache.MaxItems = 2
+				for _, key := range []string{"42.0_23.0_2020-07-10", "42.0_23.0_2020-07-11", "42.0_23.0_2020-07-12"} {
+					Expect(memoryCache.PutPermanent(key, &handler.CachedWeather{TempMax: 28.5})).To(Succeed())
+				}
+			})
+
+			It("should evict some to stay within the bound", func() {
+				held := 0
+				for _, key := range []string{"42.0_23.0_2020-07-10", "42.0_23.0_2020-07-11", "42.0_23.0_2020-07-12"} {
+					if res, _ := memoryCache.Get(key); res != nil {
+						held++
+					}
+				}
+				Expect(held).To(Equal(2))
+
+				res, err := memoryCache.Get("42.0_23.0_2020-07-12")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).ToNot(BeNil())
+			})
+		})
+	})
+})
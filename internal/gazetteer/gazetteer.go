@@ -0,0 +1,203 @@
+package gazetteer
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"weather-service/internal/handler"
+)
+
+// cities is a small GeoNames extract used when no gazetteer file is configured.
+//
+//go:embed data/cities.tsv
+var cities string
+
+// GeoNames "cities" dump columns, see https://download.geonames.org/export/dump/readme.txt
+const (
+	colName           = 1
+	colASCIIName      = 2
+	colAlternateNames = 3
+	colLatitude       = 4
+	colLongitude      = 5
+	colCountryCode    = 8
+	colPopulation     = 14
+	colTimezone       = 17
+	minColumns        = 15
+)
+
+// Gazetteer resolves place names to coordinates from an in-memory index.
+type Gazetteer struct {
+	index map[string][]handler.Place
+	zones []zonePoint
+}
+
+// maxZoneDistance is how far, in km, the nearest place may be for its
+// timezone to be used. Farther away the bundled extract would guess another
+// continent's timezone.
+const maxZoneDistance = 150
+
+// earthRadius is the mean radius of the earth in km.
+const earthRadius = 6371
+
+// zonePoint is a place with a known timezone, used for nearest-place
+// timezone lookups.
+type zonePoint struct {
+	lat, lon float64
+	timezone string
+}
+
+// New loads the gazetteer from path, or the one bundled with the binary when
+// path is empty.
+func New(path string) (*Gazetteer, error) {
+	if path == "" {
+		return Load(strings.NewReader(cities))
+	}
+	return LoadFile(path)
+}
+
+// LoadFile loads a GeoNames cities TSV file, e.g. cities15000.txt.
+func LoadFile(path string) (*Gazetteer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open gazetteer file: %w", err)
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+// Load indexes every place of a GeoNames cities TSV by its name, ASCII name
+// and alternate names.
+func Load(r io.Reader) (*Gazetteer, error) {
+	g := &Gazetteer{index: make(map[string][]handler.Place)}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if scanner.Text() == "" || strings.HasPrefix(scanner.Text(), "#") {
+			continue
+		}
+
+		cols := strings.Split(scanner.Text(), "\t")
+		if len(cols) < minColumns {
+			return nil, fmt.Errorf("invalid gazetteer line %d: expected at least %d columns, got %d", line, minColumns, len(cols))
+		}
+
+		population, _ := strconv.ParseInt(cols[colPopulation], 10, 64)
+		place := handler.Place{
+			Name:       cols[colName],
+			Country:    cols[colCountryCode],
+			Latitude:   cols[colLatitude],
+			Longitude:  cols[colLongitude],
+			Population: population,
+		}
+
+		names := []string{cols[colName], cols[colASCIIName]}
+		if cols[colAlternateNames] != "" {
+			names = append(names, strings.Split(cols[colAlternateNames], ",")...)
+		}
+		g.add(place, names)
+		g.addZone(cols)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read gazetteer: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"names": len(g.index),
+		"zones": len(g.zones),
+	}).Info("Loaded gazetteer")
+
+	return g, nil
+}
+
+func (g *Gazetteer) add(place handler.Place, names []string) {
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		key := normalize(name)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		g.index[key] = append(g.index[key], place)
+	}
+}
+
+func (g *Gazetteer) addZone(cols []string) {
+	if len(cols) <= colTimezone || cols[colTimezone] == "" {
+		return
+	}
+	lat, err := strconv.ParseFloat(cols[colLatitude], 64)
+	if err != nil {
+		return
+	}
+	lon, err := strconv.ParseFloat(cols[colLongitude], 64)
+	if err != nil {
+		return
+	}
+	g.zones = append(g.zones, zonePoint{lat: lat, lon: lon, timezone: cols[colTimezone]})
+}
+
+// Timezone returns the IANA timezone of the place nearest to lat/lon, or an
+// empty string when it is unknown or no place is within maxZoneDistance. It
+// approximates timezone boundaries, which is good enough wherever the
+// gazetteer is dense.
+func (g *Gazetteer) Timezone(lat, lon string) string {
+	latitude, err := strconv.ParseFloat(lat, 64)
+	if err != nil {
+		return ""
+	}
+	longitude, err := strconv.ParseFloat(lon, 64)
+	if err != nil {
+		return ""
+	}
+
+	timezone := ""
+	nearest := float64(maxZoneDistance) / earthRadius
+	for _, zone := range g.zones {
+		if d := distance(latitude, longitude, zone.lat, zone.lon); d < nearest {
+			nearest = d
+			timezone = zone.timezone
+		}
+	}
+	return timezone
+}
+
+// distance is the central angle between two points, in radians.
+func distance(lat1, lon1, lat2, lon2 float64) float64 {
+	const rad = math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * math.Asin(math.Sqrt(a))
+}
+
+// Lookup returns the places called name, optionally restricted to a country
+// (ISO 3166 alpha-2 code), most populated first.
+func (g *Gazetteer) Lookup(name, country string) []handler.Place {
+	var places []handler.Place
+	for _, place := range g.index[normalize(name)] {
+		if country != "" && !strings.EqualFold(place.Country, country) {
+			continue
+		}
+		places = append(places, place)
+	}
+
+	sort.SliceStable(places, func(i, j int) bool {
+		return places[i].Population > places[j].Population
+	})
+	return places
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -0,0 +1,13 @@
+package gazetteer_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestGazetteer(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Gazetteer Suite")
+}
@@ -0,0 +1,93 @@
+package gazetteer_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"strings"
+	"weather-service/internal/gazetteer"
+)
+
+var _ = Describe("Gazetteer", func() {
+	Context("Embedded", func() {
+		var g *gazetteer.Gazetteer
+
+		BeforeEach(func() {
+			var err error
+			g, err = gazetteer.New("")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		When("name is unique", func() {
+			It("should return the place", func() {
+				places := g.Lookup("Sofia", "")
+				Expect(places).To(HaveLen(1))
+				Expect(places[0].Country).To(Equal("BG"))
+				Expect(places[0].Latitude).To(Equal("42.69751"))
+				Expect(places[0].Longitude).To(Equal("23.32415"))
+			})
+		})
+
+		When("alternate name and different casing are used", func() {
+			It("should return the place", func() {
+				places := g.Lookup("  софия ", "")
+				Expect(places).To(HaveLen(1))
+				Expect(places[0].Name).To(Equal("Sofia"))
+			})
+		})
+
+		When("name is ambiguous", func() {
+			It("should return every candidate, most populated first", func() {
+				places := g.Lookup("london", "")
+				Expect(places).To(HaveLen(2))
+				Expect(places[0].Country).To(Equal("GB"))
+				Expect(places[1].Country).To(Equal("CA"))
+			})
+
+			It("should narrow down by country", func() {
+				places := g.Lookup("london", "ca")
+				Expect(places).To(HaveLen(1))
+				Expect(places[0].Latitude).To(Equal("42.98339"))
+			})
+		})
+
+		When("name is unknown", func() {
+			It("should return nothing", func() {
+				Expect(g.Lookup("Atlantis", "")).To(BeEmpty())
+			})
+		})
+
+		When("timezone of a location is requested", func() {
+			It("should return the timezone of the nearest place", func() {
+				Expect(g.Timezone("42.70", "23.32")).To(Equal("Europe/Sofia"))
+				Expect(g.Timezone("35.00", "139.00")).To(Equal("Asia/Tokyo"))
+				Expect(g.Timezone("34.00", "-118.00")).To(Equal("America/Los_Angeles"))
+			})
+
+			It("should return nothing when no place is close enough", func() {
+				Expect(g.Timezone("21.31", "-157.86")).To(BeEmpty())
+				Expect(g.Timezone("19.08", "72.88")).To(BeEmpty())
+			})
+
+			It("should return nothing for invalid coordinates", func() {
+				Expect(g.Timezone("north", "23.32")).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("Load", func() {
+		When("a line has too few columns", func() {
+			It("should return error", func() {
+				_, err := gazetteer.Load(strings.NewReader("727011\tSofia\tSofia\n"))
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid gazetteer line 1"))
+			})
+		})
+
+		When("file does not exist", func() {
+			It("should return error", func() {
+				_, err := gazetteer.New("/does/not/exist.tsv")
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"github.com/aws/aws-lambda-go/events"
+	"strings"
+)
+
+// HandleRequest adapts API Gateway REST API (payload v1.0) events to Handle.
+func (wsvc *WeatherService) HandleRequest(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	res := wsvc.Handle(ctx, Request{
+		Method:          req.HTTPMethod,
+		Path:            req.Path,
+		QueryParameters: req.QueryStringParameters,
+		Headers:         lowerHeaders(req.Headers),
+		Body:            eventBody(req.Body, req.IsBase64Encoded),
+	})
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: res.StatusCode,
+		Body:       res.Body,
+		Headers:    res.Headers,
+	}, nil
+}
+
+// HandleHTTPRequest adapts API Gateway HTTP API (payload v2.0) events to Handle.
+func (wsvc *WeatherService) HandleHTTPRequest(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	res := wsvc.Handle(ctx, Request{
+		Method:          req.RequestContext.HTTP.Method,
+		Path:            req.RawPath,
+		QueryParameters: req.QueryStringParameters,
+		Headers:         lowerHeaders(req.Headers),
+		Body:            eventBody(req.Body, req.IsBase64Encoded),
+	})
+
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode: res.StatusCode,
+		Body:       res.Body,
+		Headers:    res.Headers,
+	}, nil
+}
+
+// lowerHeaders normalises header names, since v1 events keep the client's
+// casing while v2 events are already lower-cased.
+func lowerHeaders(headers map[string]string) map[string]string {
+	lowered := make(map[string]string, len(headers))
+	for name, value := range headers {
+		lowered[strings.ToLower(name)] = value
+	}
+	return lowered
+}
+
+// eventBody returns the raw request body, decoding it when API Gateway
+// delivered it base64 encoded.
+func eventBody(body string, isBase64Encoded bool) string {
+	if !isBase64Encoded {
+		return body
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return body
+	}
+	return string(decoded)
+}
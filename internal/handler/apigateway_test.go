@@ -0,0 +1,117 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+	"weather-service/helper/mockutil"
+	"weather-service/internal/handler"
+	"weather-service/internal/handler/mocks"
+)
+
+var _ = Describe("APIGateway", mockutil.Mockable(func(helper *mockutil.Helper) {
+	var (
+		mockCache          *mocks.MockCache
+		mockForecastClient *mocks.MockForecastClient
+		ws                 *handler.WeatherService
+	)
+
+	today := time.Now().UTC().Format("2006-01-02")
+	uvIndex, rainProbability := 3.0, 0.0
+	expectedBody := fmt.Sprintf("{\"date\":\"%s\",\"latitude\":\"42.00\",\"longitude\":\"23.00\",\"temperature\":23,\"uvIndex\":3,"+moderateUVJSON+"\"rainProbability\":0,\"summary\":\"High of 23°C, low chance of rain, moderate UV\",\"units\":{\"temperature\":\"celsius\",\"precipitation\":\"mm\",\"windSpeed\":\"kmh\"},\"timezone\":\"UTC\"}", today)
+
+	BeforeEach(func() {
+		mockCache = mocks.NewMockCache(helper.Controller())
+		mockForecastClient = mocks.NewMockForecastClient(helper.Controller())
+		ws = handler.NewWeatherService(mockForecastClient, mockCache)
+	})
+
+	Context("REST API payload v1.0", func() {
+		When("cache return data", func() {
+			BeforeEach(func() {
+				mockCache.EXPECT().Get(fmt.Sprintf("42.00_23.00_%s", today)).Return(&handler.CachedWeather{
+					Key:      fmt.Sprintf("42.00_23.00_%s", today),
+					TempMax:  23,
+					UVIndex:  &uvIndex,
+					RainProb: &rainProbability,
+				}, nil).Times(1)
+			})
+
+			It("should return the weather as v1 response", func() {
+				req := events.APIGatewayProxyRequest{
+					QueryStringParameters: map[string]string{
+						"lat":  "42.0",
+						"lon":  "23.0",
+						"date": today,
+					},
+				}
+				res, err := ws.HandleRequest(context.TODO(), req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res.StatusCode).To(Equal(200))
+				Expect(res.Headers).To(HaveKeyWithValue("Content-Type", "application/json"))
+				Expect(res.Body).To(Equal(expectedBody))
+			})
+		})
+
+		When("latitude or longitude is not provided", func() {
+			It("should return error response", func() {
+				req := events.APIGatewayProxyRequest{
+					QueryStringParameters: map[string]string{"lon": "23.0"},
+				}
+				res, err := ws.HandleRequest(context.TODO(), req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res.StatusCode).To(Equal(400))
+				Expect(res.Body).To(ContainSubstring("Missing lat/lon"))
+			})
+		})
+	})
+
+	Context("HTTP API payload v2.0", func() {
+		When("cache return data", func() {
+			BeforeEach(func() {
+				mockCache.EXPECT().Get(fmt.Sprintf("42.00_23.00_%s", today)).Return(&handler.CachedWeather{
+					Key:      fmt.Sprintf("42.00_23.00_%s", today),
+					TempMax:  23,
+					UVIndex:  &uvIndex,
+					RainProb: &rainProbability,
+				}, nil).Times(1)
+				mockForecastClient.EXPECT().GetForecast(gomock.Any(), gomock.Any()).Times(0)
+			})
+
+			It("should return the weather as v2 response", func() {
+				req := events.APIGatewayV2HTTPRequest{
+					Version:  "2.0",
+					RawPath:  "/weather",
+					RouteKey: "GET /weather",
+					QueryStringParameters: map[string]string{
+						"lat":  "42.0",
+						"lon":  "23.0",
+						"date": today,
+					},
+				}
+				res, err := ws.HandleHTTPRequest(context.TODO(), req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res.StatusCode).To(Equal(200))
+				Expect(res.Headers).To(HaveKeyWithValue("Content-Type", "application/json"))
+				Expect(res.Body).To(Equal(expectedBody))
+			})
+		})
+
+		When("latitude or longitude is not provided", func() {
+			It("should return error response", func() {
+				req := events.APIGatewayV2HTTPRequest{
+					Version:               "2.0",
+					QueryStringParameters: map[string]string{"lat": "42.0"},
+				}
+				res, err := ws.HandleHTTPRequest(context.TODO(), req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res.StatusCode).To(Equal(400))
+				Expect(res.Body).To(ContainSubstring("Missing lat/lon"))
+			})
+		})
+	})
+}))
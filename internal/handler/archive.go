@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"cmp"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"time"
+	"weather-service/internal/logging"
+)
+
+//go:generate mockgen --source=archive.go --destination mocks/archive.go --package mocks
+
+// archiveStartDate is the first date the archive has data for.
+const archiveStartDate = "1940-01-01"
+
+// historicalMaxAge is how long clients may cache past days, which do not change.
+const historicalMaxAge = 365 * 24 * time.Hour
+
+// ArchiveClient fetches observed daily values for past dates.
+type ArchiveClient interface {
+	// GetArchive fetches DefaultArchiveVariables and the given extra fields.
+	GetArchive(lat, long, start, end string, fields ...string) (ForecastMap, error)
+}
+
+// getHistoricalWeather serves a single past day from the archive.
+func (wsvc *WeatherService) getHistoricalWeather(query weatherQuery, fields []string) (WeatherServiceResponse, error) {
+	fm, err := wsvc.getArchive(query.Lat, query.Lon, query.Date, query.Date, fields)
+	if err != nil {
+		return WeatherServiceResponse{}, err
+	}
+
+	forecast, ok := fm[query.Date]
+	if !ok {
+		errId := logging.LogError(fmt.Errorf("archive data not found"), map[string]interface{}{"lat": query.Lat, "lon": query.Lon, "date": query.Date})
+		return WeatherServiceResponse{}, archiveNotAvailableError(errId, query.Date)
+	}
+
+	wsr := ForecastToWeatherServiceResponse(query.Date, forecast)
+	wsr.Timezone = cmp.Or(wsr.Timezone, query.Timezone)
+	wsr.expiresAt = historicalExpiresAt()
+	return wsr, nil
+}
+
+// getArchive fetches the days from start to end, inclusive, and caches them
+// without expiry. Fields the archive does not provide are not asked for.
+func (wsvc *WeatherService) getArchive(lat, lon, start, end string, fields []string) (ForecastMap, error) {
+	logrus.WithFields(logrus.Fields{
+		"lat":   lat,
+		"lon":   lon,
+		"start": start,
+		"end":   end,
+	}).Info("Going to fetch historical weather from archive")
+
+	var archiveFields []string
+	for _, name := range fields {
+		if v, _ := LookupVariable(name); v.Archive {
+			archiveFields = append(archiveFields, name)
+		}
+	}
+
+	fm, err := wsvc.ArchiveClient.GetArchive(lat, lon, start, end, archiveFields...)
+	if err != nil {
+		errId := logging.LogError(err, map[string]interface{}{"lat": lat, "lon": lon, "start": start, "end": end})
+		return nil, weatherProviderError(errId)
+	}
+	fm = wsvc.fromProvider(fm, lat, lon)
+
+	for date, forecast := range fm {
+		keyStore := fmt.Sprintf("%s_%s_%s", lat, lon, date)
+		if err := wsvc.WeatherCache.PutPermanent(keyStore, ForecastToCachedData(forecast)); err != nil {
+			logging.LogError(err, map[string]interface{}{"key": keyStore})
+		}
+	}
+	return fm, nil
+}
+
+// archiveEnd returns the first date that is served by the ForecastClient
+// rather than the archive, PastDays before today in loc.
+func (wsvc *WeatherService) archiveEnd(loc *time.Location) string {
+	return time.Now().In(loc).AddDate(0, 0, -wsvc.PastDays).Format(dateLayout)
+}
+
+// archived reports whether cached was stored by getArchive. A past day may
+// still be cached as the forecast made before it ended, which is not what was
+// observed.
+func archived(cached *CachedWeather) bool {
+	return cached.TTL == 0
+}
+
+// historicalExpiresAt is the expiry advertised to clients for past days.
+func historicalExpiresAt() int64 {
+	return time.Now().Add(historicalMaxAge).Unix()
+}
@@ -0,0 +1,249 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+	"weather-service/helper/mockutil"
+	"weather-service/internal/handler"
+	"weather-service/internal/handler/mocks"
+)
+
+var _ = Describe("Archive", mockutil.Mockable(func(helper *mockutil.Helper) {
+	var (
+		mockCache          *mocks.MockCache
+		mockForecastClient *mocks.MockForecastClient
+		mockArchiveClient  *mocks.MockArchiveClient
+		ws                 *handler.WeatherService
+	)
+
+	today := time.Now().UTC().Format("2006-01-02")
+	lastWeek := time.Now().UTC().AddDate(0, 0, -7).Format("2006-01-02")
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02")
+
+	BeforeEach(func() {
+		mockCache = mocks.NewMockCache(helper.Controller())
+		mockForecastClient = mocks.NewMockForecastClient(helper.Controller())
+		mockArchiveClient = mocks.NewMockArchiveClient(helper.Controller())
+		ws = handler.NewWeatherService(mockForecastClient, mockCache)
+		ws.ArchiveClient = mockArchiveClient
+	})
+
+	When("a past date is not cached", func() {
+		BeforeEach(func() {
+			key := fmt.Sprintf("42.00_23.00_%s", lastWeek)
+			mockCache.EXPECT().Get(key).Return(nil, nil).Times(1)
+			mockArchiveClient.EXPECT().GetArchive("42.00", "23.00", lastWeek, lastWeek).Return(handler.ForecastMap{
+				lastWeek: handler.Forecast{Latitude: "42.0", Longitude: "23.0", Temp2max: 18},
+			}, nil).Times(1)
+			mockCache.EXPECT().PutPermanent(key, gomock.Any()).Return(nil).Times(1)
+			mockCache.EXPECT().Put(gomock.Any(), gomock.Any()).Times(0)
+			mockForecastClient.EXPECT().GetForecast(gomock.Any(), gomock.Any()).Times(0)
+		})
+
+		It("should fetch it from the archive and cache it permanently", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": lastWeek},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Headers["Cache-Control"]).To(MatchRegexp(`^public, max-age=315(35999|36000)$`))
+
+			var wsr handler.WeatherServiceResponse
+			Expect(json.Unmarshal([]byte(res.Body), &wsr)).To(Succeed())
+			Expect(wsr.Date).To(Equal(lastWeek))
+			Expect(wsr.Latitude).To(Equal("42.00"))
+			Expect(wsr.Temperature).To(Equal(18.0))
+			Expect(wsr.RainProbability).To(BeNil())
+			Expect(wsr.UVIndex).To(BeNil())
+			Expect(wsr.UVCategory).To(BeEmpty())
+			Expect(wsr.UVProtection).To(BeNil())
+		})
+	})
+
+	When("a past date is cached", func() {
+		BeforeEach(func() {
+			key := fmt.Sprintf("42.00_23.00_%s", lastWeek)
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{Key: key, TempMax: 18}, nil).Times(1)
+			mockArchiveClient.EXPECT().GetArchive(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		})
+
+		It("should return it from cache", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": lastWeek},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Body).To(ContainSubstring(`"temperature":18`))
+		})
+	})
+
+	When("a past date is cached as a forecast", func() {
+		BeforeEach(func() {
+			key := fmt.Sprintf("42.00_23.00_%s", yesterday)
+			rainProbability := 80.0
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{Key: key, TempMax: 25, RainProb: &rainProbability, TTL: time.Now().Add(time.Hour).Unix()}, nil).Times(1)
+			mockArchiveClient.EXPECT().GetArchive("42.00", "23.00", yesterday, yesterday).Return(handler.ForecastMap{
+				yesterday: handler.Forecast{Latitude: "42.0", Longitude: "23.0", Temp2max: 18},
+			}, nil).Times(1)
+			mockCache.EXPECT().PutPermanent(key, gomock.Any()).Return(nil).Times(1)
+		})
+
+		It("should fetch it from the archive instead", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": yesterday},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Body).To(ContainSubstring(`"temperature":18`))
+			Expect(res.Body).ToNot(ContainSubstring(`rainProbability`))
+		})
+
+		It("should fetch it from the archive in a range too", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "start": yesterday, "end": yesterday},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Body).To(ContainSubstring(`"temperature":18`))
+		})
+	})
+
+	When("a range spans past and upcoming days", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(gomock.Any()).Return(nil, nil).Times(2)
+			mockArchiveClient.EXPECT().GetArchive("42.00", "23.00", yesterday, yesterday).Return(handler.ForecastMap{
+				yesterday: handler.Forecast{Temp2max: 18},
+			}, nil).Times(1)
+			mockCache.EXPECT().PutPermanent(fmt.Sprintf("42.00_23.00_%s", yesterday), gomock.Any()).Return(nil).Times(1)
+			mockForecastClient.EXPECT().GetForecast("42.00", "23.00").Return(handler.ForecastMap{
+				today: handler.Forecast{Temp2max: 21},
+			}, nil).Times(1)
+			mockCache.EXPECT().Put(fmt.Sprintf("42.00_23.00_%s", today), gomock.Any()).Return(nil).Times(1)
+		})
+
+		It("should use the archive for the past and the forecast for the rest", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "start": yesterday, "end": today},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+
+			var days []handler.WeatherServiceResponse
+			Expect(json.Unmarshal([]byte(res.Body), &days)).To(Succeed())
+			Expect(days).To(HaveLen(2))
+			Expect(days[0].Temperature).To(Equal(18.0))
+			Expect(days[1].Temperature).To(Equal(21.0))
+		})
+	})
+
+	When("a recent past date is not archived yet", func() {
+		BeforeEach(func() {
+			ws.PastDays = 5
+			mockCache.EXPECT().Get(fmt.Sprintf("42.00_23.00_%s", yesterday)).Return(nil, nil).Times(1)
+			mockArchiveClient.EXPECT().GetArchive(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			mockForecastClient.EXPECT().GetForecast("42.00", "23.00").Return(handler.ForecastMap{
+				yesterday: handler.Forecast{Temp2max: 19},
+				today:     handler.Forecast{Temp2max: 21},
+			}, nil).Times(1)
+			mockCache.EXPECT().Put(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+		})
+
+		It("should serve it from the forecast", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": yesterday},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Body).To(ContainSubstring(`"temperature":19`))
+			Expect(res.Headers["Cache-Control"]).ToNot(MatchRegexp(`max-age=315`))
+		})
+	})
+
+	When("the archive does not have a past date yet", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(gomock.Any()).Return(nil, nil).AnyTimes()
+			mockArchiveClient.EXPECT().GetArchive("42.00", "23.00", gomock.Any(), yesterday).Return(handler.ForecastMap{
+				lastWeek: handler.Forecast{Temp2max: 17},
+			}, nil).Times(1)
+			mockCache.EXPECT().PutPermanent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		})
+
+		It("should report that the archive runs behind", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": yesterday},
+			})
+			Expect(res.StatusCode).To(Equal(404))
+			Expect(res.Body).To(ContainSubstring(`"code":"archive-not-available"`))
+		})
+
+		It("should leave it out of a range", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "start": lastWeek, "end": yesterday},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+
+			var days []handler.WeatherServiceResponse
+			Expect(json.Unmarshal([]byte(res.Body), &days)).To(Succeed())
+			Expect(days).To(HaveLen(1))
+			Expect(days[0].Date).To(Equal(lastWeek))
+		})
+	})
+
+	When("batch items ask for past dates", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(gomock.Any()).Return(nil, nil).Times(2)
+			mockArchiveClient.EXPECT().GetArchive("42.00", "23.00", lastWeek, yesterday).Return(handler.ForecastMap{
+				lastWeek:  handler.Forecast{Temp2max: 17},
+				yesterday: handler.Forecast{Temp2max: 18},
+			}, nil).Times(1)
+			mockCache.EXPECT().PutPermanent(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+			mockForecastClient.EXPECT().GetForecasts(gomock.Any()).Times(0)
+		})
+
+		It("should fetch every location once from the archive", func() {
+			body := fmt.Sprintf(`[{"lat":42.0,"lon":23.0,"date":"%s"},{"lat":42.0,"lon":23.0,"date":"%s"}]`, yesterday, lastWeek)
+			res := ws.Handle(context.TODO(), handler.Request{Method: "POST", Path: "/weather/batch", Body: body})
+			Expect(res.StatusCode).To(Equal(200))
+
+			var results []handler.BatchItemResult
+			Expect(json.Unmarshal([]byte(res.Body), &results)).To(Succeed())
+			Expect(results[0].Weather.Temperature).To(Equal(18.0))
+			Expect(results[1].Weather.Temperature).To(Equal(17.0))
+		})
+	})
+
+	When("the archive fails", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(gomock.Any()).Return(nil, nil).Times(1)
+			mockArchiveClient.EXPECT().GetArchive(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("some error")).Times(1)
+		})
+
+		It("should return error response", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": lastWeek},
+			})
+			Expect(res.StatusCode).To(Equal(500))
+			Expect(res.Body).To(ContainSubstring("Weather api error"))
+		})
+	})
+
+	When("the date is before the archive starts", func() {
+		It("should return error response", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": "1939-12-31"},
+			})
+			Expect(res.StatusCode).To(Equal(400))
+			Expect(res.Body).To(ContainSubstring("Date could not be before 1940-01-01"))
+		})
+	})
+
+	When("hourly data is asked for a past date", func() {
+		It("should return error response", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				Path:            "/weather/hourly",
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": yesterday},
+			})
+			Expect(res.StatusCode).To(Equal(400))
+			Expect(res.Body).To(ContainSubstring("Date could not be older than today"))
+		})
+	})
+}))
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const icsLineLimit = 75
+
+// calendarRequest turns a /weather/calendar request into a range request for
+// the whole forecast window of forecastDays days, rendered as iCalendar.
+func calendarRequest(req Request, forecastDays int) Request {
+	query := make(map[string]string, len(req.QueryParameters)+2)
+	for k, v := range req.QueryParameters {
+		query[k] = v
+	}
+	query["format"] = "ics"
+	if !isRangeRequest(req) {
+		query["days"] = strconv.Itoa(forecastDays)
+	}
+	req.QueryParameters = query
+	return req
+}
+
+// encodeICS renders every day as an all-day VEVENT. UIDs only depend on the
+// location and date, so calendar clients replace events when they refresh.
+func encodeICS(days []WeatherServiceResponse, _ bool) ([]byte, error) {
+	var b strings.Builder
+	// DTSTAMP only changes once a day, so the body and its ETag stay stable
+	// while the forecast does not change.
+	dtStamp := time.Now().UTC().Format("20060102") + "T000000Z"
+
+	writeICSLine(&b, "BEGIN:VCALENDAR")
+	writeICSLine(&b, "VERSION:2.0")
+	writeICSLine(&b, "PRODID:-//weather-service//forecast//EN")
+	writeICSLine(&b, "CALSCALE:GREGORIAN")
+	writeICSLine(&b, "METHOD:PUBLISH")
+	if len(days) > 0 {
+		writeICSLine(&b, "X-WR-CALNAME:"+escapeICSText(fmt.Sprintf("Weather %s, %s", days[0].Latitude, days[0].Longitude)))
+	}
+
+	for _, day := range days {
+		date, err := time.Parse("2006-01-02", day.Date)
+		if err != nil {
+			return nil, err
+		}
+
+		symbol := "°C"
+		if day.Units != nil && day.Units.Temperature == Fahrenheit {
+			symbol = "°F"
+		}
+		temperature := strconv.FormatFloat(day.Temperature, 'f', -1, 64) + symbol
+		uvIndex := "unknown"
+		if day.UVIndex != nil {
+			uvIndex = strconv.FormatFloat(*day.UVIndex, 'f', -1, 64)
+		}
+		rainProbability := "unknown"
+		if day.RainProbability != nil {
+			rainProbability = strconv.FormatFloat(*day.RainProbability, 'f', -1, 64) + "%"
+		}
+
+		writeICSLine(&b, "BEGIN:VEVENT")
+		writeICSLine(&b, fmt.Sprintf("UID:%s_%s_%s@weather-service", day.Latitude, day.Longitude, day.Date))
+		writeICSLine(&b, "DTSTAMP:"+dtStamp)
+		writeICSLine(&b, "DTSTART;VALUE=DATE:"+date.Format("20060102"))
+		writeICSLine(&b, "DTEND;VALUE=DATE:"+date.AddDate(0, 0, 1).Format("20060102"))
+		writeICSLine(&b, "SUMMARY:"+escapeICSText(fmt.Sprintf("%s, UV %s, rain %s", temperature, uvIndex, rainProbability)))
+		writeICSLine(&b, "DESCRIPTION:"+escapeICSText(fmt.Sprintf("Max temperature: %s\nUV index: %s\nRain probability: %s", temperature, uvIndex, rainProbability)))
+		writeICSLine(&b, "TRANSP:TRANSPARENT")
+		writeICSLine(&b, "END:VEVENT")
+	}
+
+	writeICSLine(&b, "END:VCALENDAR")
+	return []byte(b.String()), nil
+}
+
+// writeICSLine writes a CRLF terminated content line, folded at 75 octets
+// as required by RFC 5545.
+func writeICSLine(b *strings.Builder, line string) {
+	for len(line) > icsLineLimit {
+		cut := icsLineLimit
+		// do not split multi-byte characters
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
+
+func escapeICSText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,83 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"strings"
+	"time"
+	"weather-service/helper/mockutil"
+	"weather-service/internal/handler"
+	"weather-service/internal/handler/mocks"
+)
+
+var _ = Describe("Calendar", mockutil.Mockable(func(helper *mockutil.Helper) {
+	var (
+		mockCache          *mocks.MockCache
+		mockForecastClient *mocks.MockForecastClient
+		ws                 *handler.WeatherService
+	)
+
+	today := time.Now().UTC()
+	uvIndex := 5.5
+	rainProbability := 30.0
+
+	BeforeEach(func() {
+		mockCache = mocks.NewMockCache(helper.Controller())
+		mockForecastClient = mocks.NewMockForecastClient(helper.Controller())
+		ws = handler.NewWeatherService(mockForecastClient, mockCache)
+	})
+
+	When("the calendar of a location is requested", func() {
+		BeforeEach(func() {
+			fm := handler.ForecastMap{}
+			for i := 0; i < 7; i++ {
+				date := today.AddDate(0, 0, i).Format("2006-01-02")
+				fm[date] = handler.Forecast{Latitude: "42.0", Longitude: "23.0", Temp2max: 20 + float64(i), UvIndexMax: &uvIndex, PrecipProbability: &rainProbability}
+			}
+			mockCache.EXPECT().Get(gomock.Any()).Return(nil, nil).Times(7)
+			mockForecastClient.EXPECT().GetForecast("42.00", "23.00").Return(fm, nil).Times(1)
+			mockCache.EXPECT().Put(gomock.Any(), gomock.Any()).Return(nil).Times(7)
+		})
+
+		It("should return the 7 day forecast as all-day events", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				Path:            "/weather/calendar",
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Headers).To(HaveKeyWithValue("Content-Type", "text/calendar"))
+			Expect(res.Body).To(HavePrefix("BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+			Expect(res.Body).To(HaveSuffix("END:VCALENDAR\r\n"))
+			Expect(strings.Count(res.Body, "BEGIN:VEVENT")).To(Equal(7))
+
+			date := today.Format("2006-01-02")
+			Expect(res.Body).To(ContainSubstring(fmt.Sprintf("UID:42.00_23.00_%s@weather-service\r\n", date)))
+			Expect(res.Body).To(ContainSubstring("DTSTART;VALUE=DATE:" + today.Format("20060102") + "\r\n"))
+			Expect(res.Body).To(ContainSubstring("DTEND;VALUE=DATE:" + today.AddDate(0, 0, 1).Format("20060102") + "\r\n"))
+			Expect(res.Body).To(ContainSubstring("SUMMARY:20°C\\, UV 5.5\\, rain 30%\r\n"))
+		})
+	})
+
+	When("ics format is requested on /weather", func() {
+		BeforeEach(func() {
+			date := today.Format("2006-01-02")
+			key := fmt.Sprintf("42.00_23.00_%s", date)
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{Key: key, TempMax: 25}, nil).Times(1)
+		})
+
+		It("should return a single event in fahrenheit", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "format": "ics", "units": "imperial"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(strings.Count(res.Body, "BEGIN:VEVENT")).To(Equal(1))
+			Expect(res.Body).To(ContainSubstring("SUMMARY:77°F"))
+			for _, line := range strings.Split(res.Body, "\r\n") {
+				Expect(len(line)).To(BeNumerically("<=", 75))
+			}
+		})
+	})
+}))
@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"weather-service/internal/uv"
+)
+
+const (
+	defaultChartWidth  = 800
+	defaultChartHeight = 400
+	minChartWidth      = 200
+	maxChartWidth      = 2000
+	minChartHeight     = 150
+	maxChartHeight     = 1200
+)
+
+// chartTheme holds the colours of a chart.
+type chartTheme struct {
+	Background  string
+	Text        string
+	Grid        string
+	Temperature string
+	Rain        string
+}
+
+var chartThemes = map[string]chartTheme{
+	"light": {Background: "#ffffff", Text: "#333333", Grid: "#e0e0e0", Temperature: "#e4572e", Rain: "#4a90d9"},
+	"dark":  {Background: "#1e1e1e", Text: "#dddddd", Grid: "#3a3a3a", Temperature: "#ff7f50", Rain: "#5dade2"},
+}
+
+// uvBandColors are the WHO colours of the UV risk categories.
+var uvBandColors = map[uv.Category]string{
+	uv.Low:      "#3ea72d",
+	uv.Moderate: "#fff300",
+	uv.High:     "#f18b00",
+	uv.VeryHigh: "#e53210",
+	uv.Extreme:  "#b567a4",
+}
+
+// chartOptions are the size, in pixels, and the theme of a chart.
+type chartOptions struct {
+	width  int
+	height int
+	theme  chartTheme
+}
+
+// chartRequest turns a /weather/chart.svg request into a range request for
+// the whole forecast window of forecastDays days, rendered as SVG.
+func chartRequest(req Request, forecastDays int) Request {
+	query := make(map[string]string, len(req.QueryParameters)+2)
+	for k, v := range req.QueryParameters {
+		query[k] = v
+	}
+	query["format"] = "svg"
+	if !isRangeRequest(req) {
+		query["days"] = strconv.Itoa(forecastDays)
+	}
+	req.QueryParameters = query
+	return req
+}
+
+// parseChartOptions reads width, height and theme=light|dark.
+func parseChartOptions(query map[string]string) (chartOptions, error) {
+	width, err := chartDimension(query, "width", defaultChartWidth, minChartWidth, maxChartWidth)
+	if err != nil {
+		return chartOptions{}, err
+	}
+	height, err := chartDimension(query, "height", defaultChartHeight, minChartHeight, maxChartHeight)
+	if err != nil {
+		return chartOptions{}, err
+	}
+
+	name := query["theme"]
+	if name == "" {
+		name = "light"
+	}
+	theme, ok := chartThemes[name]
+	if !ok {
+		return chartOptions{}, invalidParam("theme", fmt.Errorf("Invalid theme: %s, expected light or dark", name))
+	}
+
+	return chartOptions{width: width, height: height, theme: theme}, nil
+}
+
+func chartDimension(query map[string]string, name string, def, min, max int) (int, error) {
+	value := query[name]
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return 0, invalidParam(name, fmt.Errorf("Invalid %s: must be a number between %d and %d", name, min, max))
+	}
+	return n, nil
+}
+
+// chartEncoder returns the SVG encoder for the chart options of query.
+func chartEncoder(query map[string]string) (Encoder, error) {
+	opts, err := parseChartOptions(query)
+	if err != nil {
+		return Encoder{}, err
+	}
+	return Encoder{
+		ContentType: "image/svg+xml",
+		Encode: func(days []WeatherServiceResponse, _ bool) ([]byte, error) {
+			return renderChart(days, opts)
+		},
+		Explicit: true,
+	}, nil
+}
+
+// Margins of the plot area, which leave room for the title, the axes, the
+// UV bands and the day labels.
+const (
+	chartMarginTop    = 40
+	chartMarginRight  = 50
+	chartMarginBottom = 50
+	chartMarginLeft   = 50
+	chartUVBandHeight = 10
+)
+
+// renderChart draws the temperature of days as a line, their rain
+// probability as bars on a 0-100 % axis and their UV category as coloured
+// bands below the plot.
+func renderChart(days []WeatherServiceResponse, opts chartOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`,
+		opts.width, opts.height, opts.width, opts.height)
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="%s"/>`, opts.theme.Background)
+	if len(days) == 0 {
+		buf.WriteString(`</svg>`)
+		return buf.Bytes(), nil
+	}
+
+	symbol := "°C"
+	if days[0].Units != nil && days[0].Units.Temperature == Fahrenheit {
+		symbol = "°F"
+	}
+	title := fmt.Sprintf("Forecast for %s, %s", days[0].Latitude, days[0].Longitude)
+	fmt.Fprintf(&buf, `<text x="%d" y="24" fill="%s" font-size="16">%s</text>`, chartMarginLeft, opts.theme.Text, escapeSVGText(title))
+
+	left, top := float64(chartMarginLeft), float64(chartMarginTop)
+	plotWidth := float64(opts.width - chartMarginLeft - chartMarginRight)
+	plotHeight := float64(opts.height - chartMarginTop - chartMarginBottom)
+	bottom := top + plotHeight
+	slot := plotWidth / float64(len(days))
+
+	low, high := temperatureRange(days)
+	tempY := func(t float64) float64 {
+		return bottom - (t-low)/(high-low)*plotHeight
+	}
+
+	// grid and axes, temperature on the left and rain probability on the right
+	for i := 0; i <= 4; i++ {
+		y := bottom - float64(i)/4*plotHeight
+		fmt.Fprintf(&buf, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s"/>`, left, y, left+plotWidth, y, opts.theme.Grid)
+		temperature := low + float64(i)/4*(high-low)
+		fmt.Fprintf(&buf, `<text x="%.1f" y="%.1f" fill="%s" text-anchor="end">%s%s</text>`, left-6, y+4, opts.theme.Text, strconv.FormatFloat(math.Round(temperature), 'f', 0, 64), symbol)
+		fmt.Fprintf(&buf, `<text x="%.1f" y="%.1f" fill="%s">%d%%</text>`, left+plotWidth+6, y+4, opts.theme.Text, i*25)
+	}
+
+	points := make([]string, len(days))
+	for i, day := range days {
+		x := left + float64(i)*slot
+		center := x + slot/2
+
+		// past days have no rain probability or UV, and no bar or band
+		if day.RainProbability != nil {
+			barHeight := *day.RainProbability / 100 * plotHeight
+			fmt.Fprintf(&buf, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s" fill-opacity="0.5"><title>%s%%</title></rect>`,
+				center-slot*0.3, bottom-barHeight, slot*0.6, barHeight, opts.theme.Rain, strconv.FormatFloat(*day.RainProbability, 'f', -1, 64))
+		}
+
+		if day.UVIndex != nil {
+			category := uv.Category(day.UVCategory)
+			fmt.Fprintf(&buf, `<rect x="%.1f" y="%.1f" width="%.1f" height="%d" fill="%s"><title>UV %s %s</title></rect>`,
+				x, bottom+4, slot, chartUVBandHeight, uvBandColors[category], strconv.FormatFloat(*day.UVIndex, 'f', -1, 64), escapeSVGText(string(category)))
+		}
+
+		label := day.Date
+		if date, err := time.Parse(dateLayout, day.Date); err == nil {
+			label = date.Format("Mon 02")
+		}
+		fmt.Fprintf(&buf, `<text x="%.1f" y="%.1f" fill="%s" text-anchor="middle">%s</text>`, center, bottom+chartUVBandHeight+22, opts.theme.Text, label)
+
+		points[i] = fmt.Sprintf("%.1f,%.1f", center, tempY(day.Temperature))
+	}
+
+	fmt.Fprintf(&buf, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`, strings.Join(points, " "), opts.theme.Temperature)
+	for i, day := range days {
+		x, y, _ := strings.Cut(points[i], ",")
+		fmt.Fprintf(&buf, `<circle cx="%s" cy="%s" r="3" fill="%s"><title>%s%s</title></circle>`, x, y, opts.theme.Temperature, strconv.FormatFloat(day.Temperature, 'f', -1, 64), symbol)
+	}
+
+	buf.WriteString(`</svg>`)
+	return buf.Bytes(), nil
+}
+
+// temperatureRange returns the bounds of the temperature axis, whole
+// degrees around the temperatures of days with some headroom.
+func temperatureRange(days []WeatherServiceResponse) (float64, float64) {
+	low, high := days[0].Temperature, days[0].Temperature
+	for _, day := range days[1:] {
+		low = math.Min(low, day.Temperature)
+		high = math.Max(high, day.Temperature)
+	}
+	return math.Floor(low) - 2, math.Ceil(high) + 2
+}
+
+func escapeSVGText(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
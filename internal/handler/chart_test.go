@@ -0,0 +1,112 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"io"
+	"strings"
+	"time"
+	"weather-service/helper/mockutil"
+	"weather-service/internal/handler"
+	"weather-service/internal/handler/mocks"
+)
+
+var _ = Describe("Chart", mockutil.Mockable(func(helper *mockutil.Helper) {
+	var (
+		mockCache          *mocks.MockCache
+		mockForecastClient *mocks.MockForecastClient
+		ws                 *handler.WeatherService
+	)
+
+	today := time.Now().UTC().Format("2006-01-02")
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+
+	BeforeEach(func() {
+		mockCache = mocks.NewMockCache(helper.Controller())
+		mockForecastClient = mocks.NewMockForecastClient(helper.Controller())
+		ws = handler.NewWeatherService(mockForecastClient, mockCache)
+	})
+
+	chart := func(params map[string]string) handler.Response {
+		query := map[string]string{"lat": "42.0", "lon": "23.0"}
+		for k, v := range params {
+			query[k] = v
+		}
+		return ws.Handle(context.TODO(), handler.Request{Path: "/weather/chart.svg", QueryParameters: query})
+	}
+
+	// wellFormed reports whether body parses as XML.
+	wellFormed := func(body string) error {
+		decoder := xml.NewDecoder(strings.NewReader(body))
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+		}
+	}
+
+	When("no range is given", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(gomock.Any()).DoAndReturn(func(key string) (*handler.CachedWeather, error) {
+				uvIndex, rainProbability := 6.5, 40.0
+				return &handler.CachedWeather{Key: key, TempMax: 25, UVIndex: &uvIndex, RainProb: &rainProbability}, nil
+			}).Times(7)
+		})
+
+		It("should chart the forecast window", func() {
+			res := chart(nil)
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Headers).To(HaveKeyWithValue("Content-Type", "image/svg+xml"))
+			Expect(wellFormed(res.Body)).To(Succeed())
+			Expect(res.Body).To(HavePrefix(`<svg xmlns="http://www.w3.org/2000/svg" width="800" height="400"`))
+			Expect(res.Body).To(ContainSubstring(`<rect width="100%" height="100%" fill="#ffffff"/>`))
+			Expect(strings.Count(res.Body, "<circle ")).To(Equal(7))
+			Expect(strings.Count(res.Body, `fill="#f18b00"><title>UV 6.5 high</title>`)).To(Equal(7))
+			Expect(strings.Count(res.Body, "<title>40%</title>")).To(Equal(7))
+		})
+	})
+
+	When("a range, size and theme are given", func() {
+		BeforeEach(func() {
+			low, extreme, dry, wet := 1.0, 11.0, 0.0, 100.0
+			mockCache.EXPECT().Get(fmt.Sprintf("42.00_23.00_%s", today)).Return(&handler.CachedWeather{Key: "42.00_23.00_" + today, TempMax: 20, UVIndex: &low, RainProb: &dry}, nil).Times(1)
+			mockCache.EXPECT().Get(fmt.Sprintf("42.00_23.00_%s", tomorrow)).Return(&handler.CachedWeather{Key: "42.00_23.00_" + tomorrow, TempMax: 30, UVIndex: &extreme, RainProb: &wet}, nil).Times(1)
+		})
+
+		It("should draw the days in that size and theme", func() {
+			res := chart(map[string]string{"days": "2", "width": "400", "height": "200", "theme": "dark"})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(wellFormed(res.Body)).To(Succeed())
+			Expect(res.Body).To(HavePrefix(`<svg xmlns="http://www.w3.org/2000/svg" width="400" height="200"`))
+			Expect(res.Body).To(ContainSubstring(`fill="#1e1e1e"`))
+			// the plot is 300x110 with two 150 wide slots, temperatures span 18-32
+			Expect(res.Body).To(ContainSubstring(`<polyline points="125.0,134.3 275.0,55.7" fill="none" stroke="#ff7f50"`))
+			Expect(res.Body).To(ContainSubstring(`<rect x="230.0" y="40.0" width="90.0" height="110.0"`))
+			Expect(res.Body).To(ContainSubstring(`fill="#3ea72d"><title>UV 1 low</title>`))
+			Expect(res.Body).To(ContainSubstring(`fill="#b567a4"><title>UV 11 extreme</title>`))
+		})
+	})
+
+	When("the chart options are invalid", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(gomock.Any()).Times(0)
+		})
+
+		DescribeTable("should return 400",
+			func(params map[string]string, message string) {
+				res := chart(params)
+				Expect(res.StatusCode).To(Equal(400))
+				Expect(res.Body).To(ContainSubstring(message))
+			},
+			Entry("width too small", map[string]string{"width": "10"}, "Invalid width: must be a number between 200 and 2000"),
+			Entry("height not a number", map[string]string{"height": "tall"}, "Invalid height: must be a number between 150 and 1200"),
+			Entry("unknown theme", map[string]string{"theme": "neon"}, "Invalid theme: neon, expected light or dark"),
+		)
+	})
+}))
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// varyHeaders are the request headers a response body depends on: Accept
+// picks the format and the User-Agent of curl and wget picks a terminal one.
+const varyHeaders = "Accept, User-Agent"
+
+// withCacheControl lets clients and CDNs cache res until the cache item it
+// was built from expires. expiresAt is a unix time, 0 when unknown, in which
+// case only the client may keep res and must revalidate it.
+func withCacheControl(res Response, expiresAt int64) Response {
+	if res.StatusCode != http.StatusOK {
+		return res
+	}
+
+	headers := make(map[string]string, len(res.Headers)+2)
+	for name, value := range res.Headers {
+		headers[name] = value
+	}
+	headers["Vary"] = varyHeaders
+
+	if expiresAt == 0 {
+		headers["Cache-Control"] = "private, no-cache"
+	} else {
+		maxAge := expiresAt - time.Now().Unix()
+		if maxAge < 0 {
+			maxAge = 0
+		}
+		headers["Cache-Control"] = fmt.Sprintf("public, max-age=%d", maxAge)
+	}
+	res.Headers = headers
+	return res
+}
+
+// conditionalResponse adds a strong ETag to successful GET responses and
+// answers 304 Not Modified when it matches the If-None-Match header.
+func conditionalResponse(req Request, res Response) Response {
+	if res.StatusCode != http.StatusOK || (req.Method != "" && req.Method != http.MethodGet && req.Method != http.MethodHead) {
+		return res
+	}
+
+	etag := computeETag(res)
+	headers := make(map[string]string, len(res.Headers)+1)
+	for name, value := range res.Headers {
+		headers[name] = value
+	}
+	headers["ETag"] = etag
+
+	if etagMatches(req.Headers["if-none-match"], etag) {
+		notModified := map[string]string{"ETag": etag}
+		for _, name := range []string{"Cache-Control", "Vary"} {
+			if value, ok := headers[name]; ok {
+				notModified[name] = value
+			}
+		}
+		return Response{StatusCode: http.StatusNotModified, Headers: notModified}
+	}
+
+	res.Headers = headers
+	return res
+}
+
+func computeETag(res Response) string {
+	sum := sha256.Sum256([]byte(res.Headers["Content-Type"] + "\n" + res.Body))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches implements the weak comparison If-None-Match requires.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"strconv"
+	"strings"
+	"time"
+	"weather-service/helper/mockutil"
+	"weather-service/internal/handler"
+	"weather-service/internal/handler/mocks"
+)
+
+var _ = Describe("Conditional", mockutil.Mockable(func(helper *mockutil.Helper) {
+	var (
+		mockCache          *mocks.MockCache
+		mockForecastClient *mocks.MockForecastClient
+		ws                 *handler.WeatherService
+	)
+
+	today := time.Now().UTC().Format("2006-01-02")
+	key := fmt.Sprintf("42.00_23.00_%s", today)
+	req := handler.Request{
+		Method:          "GET",
+		QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": today},
+		Headers:         map[string]string{},
+	}
+
+	maxAge := func(res handler.Response) int64 {
+		value, ok := strings.CutPrefix(res.Headers["Cache-Control"], "public, max-age=")
+		Expect(ok).To(BeTrue())
+		age, err := strconv.ParseInt(value, 10, 64)
+		Expect(err).ToNot(HaveOccurred())
+		return age
+	}
+
+	BeforeEach(func() {
+		mockCache = mocks.NewMockCache(helper.Controller())
+		mockForecastClient = mocks.NewMockForecastClient(helper.Controller())
+		ws = handler.NewWeatherService(mockForecastClient, mockCache)
+	})
+
+	When("weather is served from cache", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{
+				Key:     key,
+				TempMax: 23,
+				TTL:     time.Now().Add(5 * time.Minute).Unix(),
+			}, nil).AnyTimes()
+		})
+
+		It("should return an ETag and max-age from the remaining TTL", func() {
+			res := ws.Handle(context.TODO(), req)
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Headers["ETag"]).To(MatchRegexp(`^"[0-9a-f]{32}"$`))
+			Expect(maxAge(res)).To(BeNumerically("~", 300, 2))
+		})
+
+		It("should vary on the headers the representation depends on", func() {
+			res := ws.Handle(context.TODO(), req)
+			Expect(res.Headers).To(HaveKeyWithValue("Vary", "Accept, User-Agent"))
+		})
+
+		It("should return the same ETag for the same content", func() {
+			first := ws.Handle(context.TODO(), req)
+			second := ws.Handle(context.TODO(), req)
+			Expect(first.Headers["ETag"]).To(Equal(second.Headers["ETag"]))
+		})
+
+		It("should return a different ETag for a different representation", func() {
+			first := ws.Handle(context.TODO(), req)
+			csv := ws.Handle(context.TODO(), handler.Request{
+				Method:          "GET",
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": today, "format": "csv"},
+			})
+			Expect(first.Headers["ETag"]).ToNot(Equal(csv.Headers["ETag"]))
+		})
+
+		It("should return not modified when If-None-Match matches", func() {
+			etag := ws.Handle(context.TODO(), req).Headers["ETag"]
+
+			res := ws.Handle(context.TODO(), handler.Request{
+				Method:          "GET",
+				QueryParameters: req.QueryParameters,
+				Headers:         map[string]string{"if-none-match": `"other", W/` + etag},
+			})
+			Expect(res.StatusCode).To(Equal(304))
+			Expect(res.Body).To(BeEmpty())
+			Expect(res.Headers["ETag"]).To(Equal(etag))
+			Expect(res.Headers).To(HaveKey("Cache-Control"))
+			Expect(res.Headers).To(HaveKeyWithValue("Vary", "Accept, User-Agent"))
+		})
+
+		It("should return the body when If-None-Match does not match", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				Method:          "GET",
+				QueryParameters: req.QueryParameters,
+				Headers:         map[string]string{"if-none-match": `"other"`},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Body).ToNot(BeEmpty())
+		})
+	})
+
+	When("the expiry of the weather is unknown", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{Key: key, TempMax: 23}, nil).Times(1)
+		})
+
+		It("should not let shared caches store it", func() {
+			res := ws.Handle(context.TODO(), req)
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Headers).To(HaveKeyWithValue("Cache-Control", "private, no-cache"))
+		})
+	})
+
+	When("the expiry of one day of a range is unknown", func() {
+		tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{Key: key, TempMax: 23, TTL: time.Now().Add(5 * time.Minute).Unix()}, nil).Times(1)
+			tomorrowKey := fmt.Sprintf("42.00_23.00_%s", tomorrow)
+			mockCache.EXPECT().Get(tomorrowKey).Return(&handler.CachedWeather{Key: tomorrowKey, TempMax: 24}, nil).Times(1)
+		})
+
+		It("should not let shared caches store the range", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				Method:          "GET",
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "start": today, "end": tomorrow},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Headers).To(HaveKeyWithValue("Cache-Control", "private, no-cache"))
+		})
+	})
+
+	When("weather is fetched from the forecast client", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(key).Return(nil, nil).Times(1)
+			mockForecastClient.EXPECT().GetForecast("42.00", "23.00").Return(handler.ForecastMap{
+				today: handler.Forecast{Latitude: "42.0", Longitude: "23.0", Temp2max: 23},
+			}, nil).Times(1)
+			mockCache.EXPECT().Put(key, gomock.Any()).DoAndReturn(func(key string, weather *handler.CachedWeather) error {
+				weather.TTL = time.Now().Add(10 * time.Minute).Unix()
+				return nil
+			}).Times(1)
+		})
+
+		It("should return max-age from the TTL of the stored item", func() {
+			res := ws.Handle(context.TODO(), req)
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(maxAge(res)).To(BeNumerically("~", 600, 2))
+		})
+	})
+
+	When("the request fails", func() {
+		It("should not return an ETag", func() {
+			res := ws.Handle(context.TODO(), handler.Request{Method: "GET", QueryParameters: map[string]string{"lat": "42.0"}})
+			Expect(res.StatusCode).To(Equal(400))
+			Expect(res.Headers).ToNot(HaveKey("ETag"))
+		})
+	})
+}))
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// DefaultCoordinatePrecision is the number of decimals coordinates are
+// rounded to, about 1.1 km at the equator.
+const DefaultCoordinatePrecision = 2
+
+// normalizeLocation parses and range checks lat/lon and returns them in the
+// canonical form used for both cache keys and upstream requests, so that
+// e.g. "42", "42.0" and "42.00001" share the same cache entry.
+func (wsvc *WeatherService) normalizeLocation(lat, lon string) (string, string, error) {
+	if lat == "" || lon == "" {
+		return "", "", missingLocationError(lat, lon)
+	}
+
+	var invalid []InvalidParam
+	latitude, err := parseCoordinate(lat, 90)
+	if err != nil {
+		invalid = append(invalid, InvalidParam{Name: "lat", Reason: err.Error()})
+	}
+	longitude, err := parseCoordinate(lon, 180)
+	if err != nil {
+		invalid = append(invalid, InvalidParam{Name: "lon", Reason: err.Error()})
+	}
+	if len(invalid) > 0 {
+		return "", "", &serviceError{StatusCode: 400, Code: CodeInvalidParameters, Message: "Invalid lat/lon", InvalidParams: invalid}
+	}
+
+	return wsvc.formatCoordinate(latitude), wsvc.formatCoordinate(longitude), nil
+}
+
+// parseCoordinate parses a decimal degree value within [-limit, limit].
+func parseCoordinate(value string, limit float64) (float64, error) {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("must be a decimal number")
+	}
+	if v < -limit || v > limit {
+		return 0, fmt.Errorf("must be between %v and %v", -limit, limit)
+	}
+	return v, nil
+}
+
+// formatCoordinate snaps v to the configured grid, if any, and rounds it to
+// the configured precision.
+func (wsvc *WeatherService) formatCoordinate(v float64) string {
+	if wsvc.CoordinateGrid > 0 {
+		v = math.Round(v/wsvc.CoordinateGrid) * wsvc.CoordinateGrid
+	}
+	v = round(v, wsvc.CoordinatePrecision)
+	if v == 0 {
+		// avoid "-0.00" and "0.00" being different keys
+		v = 0
+	}
+	return strconv.FormatFloat(v, 'f', wsvc.CoordinatePrecision, 64)
+}
+
+// withLocation returns fm with every forecast attributed to the normalised
+// request coordinates rather than the provider's grid point, so fresh and
+// cached responses agree.
+func withLocation(fm ForecastMap, lat, lon string) ForecastMap {
+	located := make(ForecastMap, len(fm))
+	for date, forecast := range fm {
+		forecast.Latitude = lat
+		forecast.Longitude = lon
+		located[date] = forecast
+	}
+	return located
+}
+
+// withHourlyLocation is withLocation for the hours of one day.
+func withHourlyLocation(hours []HourlyForecast, lat, lon string) []HourlyForecast {
+	located := make([]HourlyForecast, len(hours))
+	for i, hour := range hours {
+		hour.Latitude = lat
+		hour.Longitude = lon
+		located[i] = hour
+	}
+	return located
+}
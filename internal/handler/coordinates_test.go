@@ -0,0 +1,138 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+	"weather-service/helper/mockutil"
+	"weather-service/internal/handler"
+	"weather-service/internal/handler/mocks"
+)
+
+var _ = Describe("Coordinates", mockutil.Mockable(func(helper *mockutil.Helper) {
+	var (
+		mockCache          *mocks.MockCache
+		mockForecastClient *mocks.MockForecastClient
+		ws                 *handler.WeatherService
+	)
+
+	today := time.Now().UTC().Format("2006-01-02")
+
+	BeforeEach(func() {
+		mockCache = mocks.NewMockCache(helper.Controller())
+		mockForecastClient = mocks.NewMockForecastClient(helper.Controller())
+		ws = handler.NewWeatherService(mockForecastClient, mockCache)
+	})
+
+	get := func(lat, lon string) handler.Response {
+		return ws.Handle(context.TODO(), handler.Request{
+			QueryParameters: map[string]string{"lat": lat, "lon": lon, "date": today},
+		})
+	}
+
+	When("equivalent coordinates are requested", func() {
+		BeforeEach(func() {
+			key := fmt.Sprintf("42.00_23.00_%s", today)
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{Key: key, TempMax: 23}, nil).Times(3)
+		})
+
+		It("should share the same cache entry", func() {
+			for _, lat := range []string{"42", "42.0", "42.00001"} {
+				res := get(lat, "23")
+				Expect(res.StatusCode).To(Equal(200))
+			}
+		})
+	})
+
+	When("the forecast is fetched from the provider", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(fmt.Sprintf("42.70_23.32_%s", today)).Return(nil, nil).Times(1)
+			mockForecastClient.EXPECT().GetForecast("42.70", "23.32").Return(handler.ForecastMap{
+				today: handler.Forecast{Latitude: "42.6875", Longitude: "23.3125", Temp2max: 21},
+			}, nil).Times(1)
+			mockCache.EXPECT().Put(fmt.Sprintf("42.70_23.32_%s", today), gomock.Any()).Return(nil).Times(1)
+		})
+
+		It("should use the normalised coordinates upstream, in the cache and in the response", func() {
+			res := get("42.69751", "23.32415")
+			Expect(res.StatusCode).To(Equal(200))
+
+			var wsr handler.WeatherServiceResponse
+			Expect(json.Unmarshal([]byte(res.Body), &wsr)).To(Succeed())
+			Expect(wsr.Latitude).To(Equal("42.70"))
+			Expect(wsr.Longitude).To(Equal("23.32"))
+		})
+	})
+
+	When("a coordinate grid is configured", func() {
+		BeforeEach(func() {
+			ws.CoordinateGrid = 0.25
+			key := fmt.Sprintf("42.75_23.25_%s", today)
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{Key: key, TempMax: 23}, nil).Times(1)
+		})
+
+		It("should snap coordinates to the grid", func() {
+			res := get("42.69751", "23.32415")
+			Expect(res.StatusCode).To(Equal(200))
+		})
+	})
+
+	When("a negative coordinate rounds to zero", func() {
+		BeforeEach(func() {
+			key := fmt.Sprintf("0.00_0.00_%s", today)
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{Key: key, TempMax: 23}, nil).Times(1)
+		})
+
+		It("should not produce a negative zero", func() {
+			res := get("-0.001", "-0.0")
+			Expect(res.StatusCode).To(Equal(200))
+		})
+	})
+
+	Context("Invalid coordinates", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(gomock.Any()).Times(0)
+			mockForecastClient.EXPECT().GetForecast(gomock.Any(), gomock.Any()).Times(0)
+		})
+
+		When("lat is out of range", func() {
+			It("should return error response", func() {
+				res := get("91", "23")
+				Expect(res.StatusCode).To(Equal(400))
+
+				var problem handler.Problem
+				Expect(json.Unmarshal([]byte(res.Body), &problem)).To(Succeed())
+				Expect(problem.Code).To(Equal(handler.CodeInvalidParameters))
+				Expect(problem.InvalidParams).To(Equal([]handler.InvalidParam{{Name: "lat", Reason: "must be between -90 and 90"}}))
+			})
+		})
+
+		When("lat and lon are not numbers", func() {
+			It("should report both parameters", func() {
+				res := get("NaN", "east")
+				Expect(res.StatusCode).To(Equal(400))
+
+				var problem handler.Problem
+				Expect(json.Unmarshal([]byte(res.Body), &problem)).To(Succeed())
+				Expect(problem.InvalidParams).To(Equal([]handler.InvalidParam{
+					{Name: "lat", Reason: "must be a decimal number"},
+					{Name: "lon", Reason: "must be a decimal number"},
+				}))
+			})
+		})
+
+		When("lon is out of range in a range request", func() {
+			It("should return error response", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42", "lon": "-180.5", "days": "2"},
+				})
+				Expect(res.StatusCode).To(Equal(400))
+				Expect(res.Body).To(ContainSubstring("must be between -180 and 180"))
+			})
+		})
+	})
+}))
@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"weather-service/internal/logging"
+)
+
+// Encoder renders daily weather responses in one output format. single is
+// set when a single date was requested rather than a range.
+type Encoder struct {
+	ContentType string
+	Encode      func(days []WeatherServiceResponse, single bool) ([]byte, error)
+	// Explicit encoders are only picked by name, never from the Accept header.
+	Explicit bool
+}
+
+// encoders is the registry of output formats, keyed by their format= name.
+var encoders = map[string]Encoder{
+	"json":   {ContentType: "application/json", Encode: encodeJSON},
+	"csv":    {ContentType: "text/csv", Encode: encodeCSV},
+	"xml":    {ContentType: "application/xml", Encode: encodeXML},
+	"ndjson": {ContentType: "application/x-ndjson", Encode: encodeNDJSON},
+	"ics":    {ContentType: "text/calendar", Encode: encodeICS},
+	"text":   {ContentType: "text/plain; charset=utf-8", Encode: encodeText},
+	"ansi":   {ContentType: "text/plain; charset=utf-8", Encode: encodeANSI, Explicit: true},
+	"plain":  {ContentType: "text/plain; charset=utf-8", Encode: encodePlain, Explicit: true},
+}
+
+const defaultFormat = "json"
+
+// negotiateEncoder picks the encoder from the format= parameter or, when it
+// is missing, from the User-Agent of terminal clients or the Accept header.
+func negotiateEncoder(req Request) (Encoder, error) {
+	if format := terminalFormat(req); format != "" {
+		return encoders[format], nil
+	}
+
+	// charts take their size and theme from the query
+	if strings.EqualFold(req.QueryParameters["format"], "svg") {
+		return chartEncoder(req.QueryParameters)
+	}
+
+	if format := req.QueryParameters["format"]; format != "" {
+		enc, ok := encoders[strings.ToLower(format)]
+		if !ok {
+			return Encoder{}, &serviceError{
+				StatusCode:    http.StatusNotAcceptable,
+				Code:          CodeNotAcceptable,
+				Message:       fmt.Sprintf("Unsupported format: %s", format),
+				InvalidParams: []InvalidParam{{Name: "format", Reason: "is not a supported format"}},
+			}
+		}
+		return enc, nil
+	}
+
+	accept := req.Headers["accept"]
+	if accept == "" {
+		return encoders[defaultFormat], nil
+	}
+
+	for _, mediaType := range acceptedMediaTypes(accept) {
+		if mediaType == "*/*" || mediaType == "application/*" {
+			return encoders[defaultFormat], nil
+		}
+		for _, enc := range encoders {
+			if contentType, _, _ := strings.Cut(enc.ContentType, ";"); contentType == mediaType && !enc.Explicit {
+				return enc, nil
+			}
+		}
+	}
+
+	return Encoder{}, &serviceError{StatusCode: http.StatusNotAcceptable, Code: CodeNotAcceptable, Message: fmt.Sprintf("Unsupported Accept header: %s", accept)}
+}
+
+// acceptedMediaTypes returns the media types of an Accept header, most
+// preferred first. Types with q=0 are dropped.
+func acceptedMediaTypes(accept string) []string {
+	type mediaRange struct {
+		mediaType string
+		quality   float64
+	}
+
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		r := mediaRange{mediaType: strings.ToLower(strings.TrimSpace(params[0])), quality: 1}
+		for _, param := range params[1:] {
+			name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && name == "q" {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					r.quality = q
+				}
+			}
+		}
+		if r.mediaType != "" && r.quality > 0 {
+			ranges = append(ranges, r)
+		}
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	mediaTypes := make([]string, len(ranges))
+	for i, r := range ranges {
+		mediaTypes[i] = r.mediaType
+	}
+	return mediaTypes
+}
+
+// respondEncoded renders days with enc.
+func respondEncoded(enc Encoder, days []WeatherServiceResponse, single bool) Response {
+	body, err := enc.Encode(days, single)
+	if err != nil {
+		errId := logging.LogError(err, map[string]interface{}{"contentType": enc.ContentType})
+		return errorResponse(internalError(errId, "Error while generating response"))
+	}
+
+	// the response expires with its first day, and is not cached publicly
+	// when the expiry of any day is unknown
+	var expiresAt int64
+	for i, day := range days {
+		if i == 0 || day.expiresAt < expiresAt {
+			expiresAt = day.expiresAt
+		}
+	}
+
+	return withCacheControl(Response{
+		StatusCode: 200,
+		Body:       string(body),
+		Headers:    map[string]string{"Content-Type": enc.ContentType},
+	}, expiresAt)
+}
+
+func encodeJSON(days []WeatherServiceResponse, single bool) ([]byte, error) {
+	if single && len(days) == 1 {
+		return json.Marshal(days[0])
+	}
+	return json.Marshal(days)
+}
+
+func encodeNDJSON(days []WeatherServiceResponse, _ bool) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, day := range days {
+		if err := enc.Encode(day); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// csvUnits are the unit columns that end every row.
+var csvUnits = []string{"temperatureUnit", "precipitationUnit", "windSpeedUnit"}
+
+// csvFields returns the variables of the columns of day, the default
+// variables when no fields were selected.
+func csvFields(day WeatherServiceResponse) []string {
+	if day.selected == nil {
+		return DefaultVariables
+	}
+	return day.selected
+}
+
+// csvColumns returns the header of days, which have the same selected fields.
+func csvColumns(days []WeatherServiceResponse) []string {
+	fields := DefaultVariables
+	if len(days) > 0 {
+		fields = csvFields(days[0])
+	}
+	header := []string{"date", "latitude", "longitude"}
+	for _, name := range fields {
+		v, _ := LookupVariable(name)
+		header = append(header, v.Field)
+	}
+	return append(header, csvUnits...)
+}
+
+func encodeCSV(days []WeatherServiceResponse, _ bool) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(csvColumns(days)); err != nil {
+		return nil, err
+	}
+	for _, day := range days {
+		var units Units
+		if day.Units != nil {
+			units = *day.Units
+		}
+		record := []string{day.Date, day.Latitude, day.Longitude}
+		for _, name := range csvFields(day) {
+			value, _ := day.value(name)
+			record = append(record, csvValue(value))
+		}
+		record = append(record, units.Temperature, units.Precipitation, units.WindSpeed)
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// csvValue formats a variable value, missing values are left empty.
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case string:
+		return v
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// encodeText writes the summary of a single day, or one "date: summary" line
+// per day of a range.
+func encodeText(days []WeatherServiceResponse, single bool) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, day := range days {
+		if single && len(days) == 1 {
+			buf.WriteString(day.Summary)
+		} else {
+			fmt.Fprintf(&buf, "%s: %s", day.Date, day.Summary)
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+type xmlForecasts struct {
+	XMLName xml.Name                 `xml:"forecasts"`
+	Days    []WeatherServiceResponse `xml:"forecast"`
+}
+
+func encodeXML(days []WeatherServiceResponse, single bool) ([]byte, error) {
+	var body []byte
+	var err error
+	if single && len(days) == 1 {
+		body, err = xml.Marshal(days[0])
+	} else {
+		body, err = xml.Marshal(xmlForecasts{Days: days})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
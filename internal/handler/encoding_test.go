@@ -0,0 +1,179 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"strings"
+	"time"
+	"weather-service/helper/mockutil"
+	"weather-service/internal/handler"
+	"weather-service/internal/handler/mocks"
+)
+
+var _ = Describe("Encoding", mockutil.Mockable(func(helper *mockutil.Helper) {
+	var (
+		mockCache          *mocks.MockCache
+		mockForecastClient *mocks.MockForecastClient
+		ws                 *handler.WeatherService
+	)
+
+	today := time.Now().UTC().Format("2006-01-02")
+	uvIndex := 3.0
+	rainProbability := 10.0
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+
+	BeforeEach(func() {
+		mockCache = mocks.NewMockCache(helper.Controller())
+		mockForecastClient = mocks.NewMockForecastClient(helper.Controller())
+		ws = handler.NewWeatherService(mockForecastClient, mockCache)
+	})
+
+	cached := func(date string, temp float64) *handler.CachedWeather {
+		return &handler.CachedWeather{Key: fmt.Sprintf("42.00_23.00_%s", date), TempMax: temp, UVIndex: &uvIndex, RainProb: &rainProbability}
+	}
+
+	Context("Single date", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(fmt.Sprintf("42.00_23.00_%s", today)).Return(cached(today, 23.5), nil).Times(1)
+		})
+
+		When("csv is requested with the format parameter", func() {
+			It("should return csv with a header row", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": today, "format": "csv"},
+				})
+				Expect(res.StatusCode).To(Equal(200))
+				Expect(res.Headers).To(HaveKeyWithValue("Content-Type", "text/csv"))
+				Expect(res.Body).To(Equal(fmt.Sprintf("date,latitude,longitude,temperature,uvIndex,rainProbability,conditionCode,sunrise,sunset,daylightDuration,"+
+					"temperatureMin,apparentTemperatureMax,apparentTemperatureMin,precipitationSum,rainSum,snowfallSum,precipitationHours,windSpeedMax,windGustsMax,windDirection,"+
+					"temperatureUnit,precipitationUnit,windSpeedUnit\n%s,42.00,23.00,23.5,3,10,,,,,,,,,,,,,,,celsius,mm,kmh\n", today)))
+			})
+		})
+
+		When("xml is requested with the Accept header", func() {
+			It("should return a single xml forecast", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": today},
+					Headers:         map[string]string{"accept": "text/html;q=0.9, application/xml"},
+				})
+				Expect(res.StatusCode).To(Equal(200))
+				Expect(res.Headers).To(HaveKeyWithValue("Content-Type", "application/xml"))
+				Expect(res.Body).To(HavePrefix("<?xml"))
+				Expect(res.Body).To(ContainSubstring(fmt.Sprintf("<forecast><date>%s</date><latitude>42.00</latitude>", today)))
+				Expect(res.Body).To(ContainSubstring("<units><temperature>celsius</temperature><precipitation>mm</precipitation><windSpeed>kmh</windSpeed></units>"))
+			})
+		})
+
+		When("text is requested", func() {
+			It("should return the summary", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": today, "format": "text", "lang": "bg"},
+				})
+				Expect(res.StatusCode).To(Equal(200))
+				Expect(res.Headers).To(HaveKeyWithValue("Content-Type", "text/plain; charset=utf-8"))
+				Expect(res.Body).To(Equal("Максимална 24°C, малка вероятност за валежи, умерен UV индекс\n"))
+			})
+
+			It("should be negotiated from the Accept header", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": today},
+					Headers:         map[string]string{"accept": "text/plain"},
+				})
+				Expect(res.StatusCode).To(Equal(200))
+				Expect(res.Body).To(Equal("High of 24°C, low chance of rain, moderate UV\n"))
+			})
+		})
+
+		When("any type is accepted", func() {
+			It("should return json", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": today},
+					Headers:         map[string]string{"accept": "*/*"},
+				})
+				Expect(res.StatusCode).To(Equal(200))
+				Expect(res.Headers).To(HaveKeyWithValue("Content-Type", "application/json"))
+				Expect(res.Body).To(HavePrefix("{"))
+			})
+		})
+	})
+
+	Context("Date range", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(fmt.Sprintf("42.00_23.00_%s", today)).Return(cached(today, 23.5), nil).Times(1)
+			mockCache.EXPECT().Get(fmt.Sprintf("42.00_23.00_%s", tomorrow)).Return(cached(tomorrow, 25), nil).Times(1)
+		})
+
+		When("ndjson is requested", func() {
+			It("should return one json object per line", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "days": "2"},
+					Headers:         map[string]string{"accept": "application/x-ndjson"},
+				})
+				Expect(res.StatusCode).To(Equal(200))
+				Expect(res.Headers).To(HaveKeyWithValue("Content-Type", "application/x-ndjson"))
+				lines := strings.Split(strings.TrimSpace(res.Body), "\n")
+				Expect(lines).To(HaveLen(2))
+				Expect(lines[1]).To(ContainSubstring(fmt.Sprintf("\"date\":\"%s\"", tomorrow)))
+			})
+		})
+
+		When("xml is requested", func() {
+			It("should wrap forecasts in a root element", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "days": "2", "format": "xml"},
+				})
+				Expect(res.StatusCode).To(Equal(200))
+				Expect(res.Body).To(ContainSubstring("<forecasts><forecast>"))
+				Expect(strings.Count(res.Body, "<forecast>")).To(Equal(2))
+			})
+		})
+
+		When("text is requested", func() {
+			It("should return one dated summary per line", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "days": "2", "format": "text"},
+				})
+				Expect(res.StatusCode).To(Equal(200))
+				Expect(res.Body).To(Equal(fmt.Sprintf("%s: High of 24°C, low chance of rain, moderate UV\n%s: High of 25°C, low chance of rain, moderate UV\n", today, tomorrow)))
+			})
+		})
+
+		When("csv is requested", func() {
+			It("should return one row per day", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "days": "2", "format": "csv"},
+				})
+				Expect(res.StatusCode).To(Equal(200))
+				Expect(strings.Split(strings.TrimSpace(res.Body), "\n")).To(HaveLen(3))
+			})
+		})
+	})
+
+	Context("Unsupported formats", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(gomock.Any()).Times(0)
+		})
+
+		When("format parameter is unknown", func() {
+			It("should return not acceptable", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "format": "yaml"},
+				})
+				Expect(res.StatusCode).To(Equal(406))
+			})
+		})
+
+		When("no accepted type is supported", func() {
+			It("should return not acceptable", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0"},
+					Headers:         map[string]string{"accept": "application/pdf, application/json;q=0"},
+				})
+				Expect(res.StatusCode).To(Equal(406))
+			})
+		})
+	})
+}))
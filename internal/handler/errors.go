@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"weather-service/internal/logging"
+)
+
+// Stable, machine-readable problem codes. They are also the last segment of
+// the problem type URI.
+const (
+	CodeInvalidParameters   = "invalid-parameters"
+	CodeInvalidBody         = "invalid-body"
+	CodePlaceNotFound       = "place-not-found"
+	CodeNotFound            = "not-found"
+	CodeForecastNotFound    = "forecast-not-found"
+	CodeArchiveNotAvailable = "archive-not-available"
+	CodeMethodNotAllowed    = "method-not-allowed"
+	CodeNotAcceptable       = "not-acceptable"
+	CodeWeatherProviderFail = "weather-provider-error"
+	CodeInternal            = "internal-error"
+)
+
+var problemTitles = map[string]string{
+	CodeInvalidParameters:   "Invalid query parameters",
+	CodeInvalidBody:         "Invalid request body",
+	CodePlaceNotFound:       "Place not found",
+	CodeNotFound:            "Not found",
+	CodeForecastNotFound:    "Weather forecast not found",
+	CodeArchiveNotAvailable: "Archive data not available yet",
+	CodeMethodNotAllowed:    "Method not allowed",
+	CodeNotAcceptable:       "Response format not supported",
+	CodeWeatherProviderFail: "Weather api error",
+	CodeInternal:            "Internal error",
+}
+
+const problemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details body.
+type Problem struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	Instance      string         `json:"instance,omitempty"`
+	Code          string         `json:"code"`
+	ErrorId       string         `json:"errorId,omitempty"`
+	InvalidParams []InvalidParam `json:"invalidParams,omitempty"`
+}
+
+// InvalidParam explains why a single request parameter was rejected.
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// serviceError is an error that maps to a problem response. ErrorId is the
+// id returned by logging.LogError, when the error was logged.
+type serviceError struct {
+	StatusCode    int
+	Code          string
+	Message       string
+	ErrorId       string
+	InvalidParams []InvalidParam
+}
+
+func (e *serviceError) Error() string {
+	if e.ErrorId == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("[%s] %s", e.ErrorId, e.Message)
+}
+
+// Problem converts e to its RFC 7807 representation.
+func (e *serviceError) Problem() Problem {
+	p := Problem{
+		Type:          "/problems/" + e.Code,
+		Title:         problemTitles[e.Code],
+		Status:        e.StatusCode,
+		Detail:        e.Message,
+		Code:          e.Code,
+		ErrorId:       e.ErrorId,
+		InvalidParams: e.InvalidParams,
+	}
+	if e.ErrorId != "" {
+		p.Instance = "urn:uuid:" + e.ErrorId
+	}
+	return p
+}
+
+// invalidParam rejects a single query parameter.
+func invalidParam(name string, err error) *serviceError {
+	return &serviceError{
+		StatusCode:    http.StatusBadRequest,
+		Code:          CodeInvalidParameters,
+		Message:       err.Error(),
+		InvalidParams: []InvalidParam{{Name: name, Reason: err.Error()}},
+	}
+}
+
+// missingLocationError reports which of lat/lon are missing.
+func missingLocationError(lat, lon string) *serviceError {
+	svcErr := &serviceError{StatusCode: http.StatusBadRequest, Code: CodeInvalidParameters, Message: "Missing lat/lon"}
+	if lat == "" {
+		svcErr.InvalidParams = append(svcErr.InvalidParams, InvalidParam{Name: "lat", Reason: "is required"})
+	}
+	if lon == "" {
+		svcErr.InvalidParams = append(svcErr.InvalidParams, InvalidParam{Name: "lon", Reason: "is required"})
+	}
+	return svcErr
+}
+
+// methodNotAllowedResponse rejects the method of a request to a path that
+// only answers allow.
+func methodNotAllowedResponse(message string, allow ...string) Response {
+	res := errorResponse(&serviceError{StatusCode: http.StatusMethodNotAllowed, Code: CodeMethodNotAllowed, Message: message})
+	res.Headers["Allow"] = strings.Join(allow, ", ")
+	return res
+}
+
+func weatherProviderError(errId string) *serviceError {
+	return &serviceError{StatusCode: http.StatusInternalServerError, Code: CodeWeatherProviderFail, Message: "Weather api error", ErrorId: errId}
+}
+
+func forecastNotFoundError(errId string) *serviceError {
+	return &serviceError{StatusCode: http.StatusNotFound, Code: CodeForecastNotFound, Message: "Weather forecast not found for this date", ErrorId: errId}
+}
+
+func archiveNotAvailableError(errId, date string) *serviceError {
+	return &serviceError{StatusCode: http.StatusNotFound, Code: CodeArchiveNotAvailable, Message: fmt.Sprintf("The archive has no observed weather for %s yet", date), ErrorId: errId}
+}
+
+func internalError(errId, message string) *serviceError {
+	return &serviceError{StatusCode: http.StatusInternalServerError, Code: CodeInternal, Message: message, ErrorId: errId}
+}
+
+// asServiceError converts err to a serviceError, treating unknown errors as internal ones.
+func asServiceError(err error) *serviceError {
+	var svcErr *serviceError
+	if errors.As(err, &svcErr) {
+		return svcErr
+	}
+
+	errId := logging.LogError(err, map[string]interface{}{})
+	return internalError(errId, "Internal error")
+}
+
+// errorResponse renders err as an application/problem+json response.
+func errorResponse(err error) Response {
+	problem := asServiceError(err).Problem()
+	body, marshalErr := json.Marshal(problem)
+	if marshalErr != nil {
+		// Problem only holds strings and ints, so this is not expected to happen
+		return Response{StatusCode: problem.Status, Body: problem.Detail}
+	}
+
+	return Response{
+		StatusCode: problem.Status,
+		Body:       string(body),
+		Headers:    map[string]string{"Content-Type": problemContentType},
+	}
+}
@@ -0,0 +1,99 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+	"weather-service/helper/mockutil"
+	"weather-service/internal/handler"
+	"weather-service/internal/handler/mocks"
+)
+
+var _ = Describe("Errors", mockutil.Mockable(func(helper *mockutil.Helper) {
+	var (
+		mockCache          *mocks.MockCache
+		mockForecastClient *mocks.MockForecastClient
+		ws                 *handler.WeatherService
+	)
+
+	today := time.Now().UTC().Format("2006-01-02")
+
+	BeforeEach(func() {
+		mockCache = mocks.NewMockCache(helper.Controller())
+		mockForecastClient = mocks.NewMockForecastClient(helper.Controller())
+		ws = handler.NewWeatherService(mockForecastClient, mockCache)
+	})
+
+	problemOf := func(res handler.Response) handler.Problem {
+		Expect(res.Headers).To(HaveKeyWithValue("Content-Type", "application/problem+json"))
+		var problem handler.Problem
+		Expect(json.Unmarshal([]byte(res.Body), &problem)).To(Succeed())
+		Expect(problem.Status).To(Equal(res.StatusCode))
+		return problem
+	}
+
+	When("latitude and longitude are missing", func() {
+		It("should return invalid parameters problem", func() {
+			res := ws.Handle(context.TODO(), handler.Request{QueryParameters: map[string]string{}})
+			Expect(res.StatusCode).To(Equal(400))
+
+			problem := problemOf(res)
+			Expect(problem.Type).To(Equal("/problems/invalid-parameters"))
+			Expect(problem.Code).To(Equal(handler.CodeInvalidParameters))
+			Expect(problem.Title).To(Equal("Invalid query parameters"))
+			Expect(problem.Detail).To(Equal("Missing lat/lon"))
+			Expect(problem.InvalidParams).To(Equal([]handler.InvalidParam{
+				{Name: "lat", Reason: "is required"},
+				{Name: "lon", Reason: "is required"},
+			}))
+		})
+	})
+
+	When("days is invalid on a range request", func() {
+		It("should point to the days parameter", func() {
+			res := ws.Handle(context.TODO(), handler.Request{QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "days": "x"}})
+			Expect(res.StatusCode).To(Equal(400))
+
+			problem := problemOf(res)
+			Expect(problem.InvalidParams).To(HaveLen(1))
+			Expect(problem.InvalidParams[0].Name).To(Equal("days"))
+		})
+	})
+
+	When("forecast client returns error", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(gomock.Any()).Return(nil, nil).Times(1)
+			mockForecastClient.EXPECT().GetForecast(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("some error")).Times(1)
+		})
+
+		It("should return the logged error id", func() {
+			res := ws.Handle(context.TODO(), handler.Request{QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": today}})
+			Expect(res.StatusCode).To(Equal(500))
+
+			problem := problemOf(res)
+			Expect(problem.Code).To(Equal(handler.CodeWeatherProviderFail))
+			Expect(problem.ErrorId).ToNot(BeEmpty())
+			Expect(problem.Instance).To(Equal("urn:uuid:" + problem.ErrorId))
+		})
+	})
+
+	When("format is not supported", func() {
+		It("should return not acceptable problem", func() {
+			res := ws.Handle(context.TODO(), handler.Request{QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "format": "yaml"}})
+			Expect(res.StatusCode).To(Equal(406))
+			Expect(problemOf(res).Code).To(Equal(handler.CodeNotAcceptable))
+		})
+	})
+
+	When("batch body is invalid", func() {
+		It("should return invalid body problem", func() {
+			res := ws.Handle(context.TODO(), handler.Request{Method: "POST", Path: "/weather/batch", Body: "nope"})
+			Expect(res.StatusCode).To(Equal(400))
+			Expect(problemOf(res).Code).To(Equal(handler.CodeInvalidBody))
+		})
+	})
+}))
@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// parseFields reads fields=, a comma-separated list of variables given by
+// their Open-Meteo or response name, and returns their Open-Meteo names in
+// the requested order. nil selects the default response fields.
+func parseFields(query map[string]string) ([]string, error) {
+	var fields []string
+	for _, name := range strings.Split(query["fields"], ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		v, ok := LookupVariable(name)
+		if !ok {
+			return nil, invalidParam("fields", fmt.Errorf("Invalid fields: unknown field %s", name))
+		}
+		fields = MergeVariables(fields, v.Name)
+	}
+	return fields, nil
+}
+
+// selectFields restricts the encoded wsr to the given variables, nil keeps
+// the default fields.
+func selectFields(wsr WeatherServiceResponse, fields []string) WeatherServiceResponse {
+	wsr.selected = fields
+	return wsr
+}
+
+// value returns the value of the variable name, which is either one of the
+// default fields or held in Fields.
+func (wsr WeatherServiceResponse) value(name string) (interface{}, bool) {
+	switch name {
+	case "temperature_2m_max":
+		return wsr.Temperature, true
+	case "uv_index_max":
+		return optional(wsr.UVIndex)
+	case "precipitation_probability_max":
+		return optional(wsr.RainProbability)
+	case "temperature_2m_min":
+		return optional(wsr.TemperatureMin)
+	case "apparent_temperature_max":
+		return optional(wsr.ApparentTemperatureMax)
+	case "apparent_temperature_min":
+		return optional(wsr.ApparentTemperatureMin)
+	case "precipitation_sum":
+		return optional(wsr.PrecipitationSum)
+	case "rain_sum":
+		return optional(wsr.RainSum)
+	case "snowfall_sum":
+		return optional(wsr.SnowfallSum)
+	case "precipitation_hours":
+		return optional(wsr.PrecipitationHours)
+	case "wind_speed_10m_max":
+		return optional(wsr.WindSpeedMax)
+	case "wind_gusts_10m_max":
+		return optional(wsr.WindGustsMax)
+	case "wind_direction_10m_dominant":
+		return optional(wsr.WindDirection)
+	case "daylight_duration":
+		return optional(wsr.DaylightDuration)
+	case "weather_code":
+		if wsr.ConditionCode == nil {
+			return nil, false
+		}
+		return *wsr.ConditionCode, true
+	case "sunrise", "sunset":
+		// the sun neither rises nor sets on polar days and nights, items
+		// with a solar noon had their sun times resolved
+		t := wsr.Sunrise
+		if name == "sunset" {
+			t = wsr.Sunset
+		}
+		if t == "" {
+			return nil, wsr.SolarNoon != ""
+		}
+		return t, true
+	case "summary":
+		// composed from the other values when the response is presented
+		return wsr.Summary, true
+	case "solar_noon":
+		if wsr.SolarNoon == "" {
+			return nil, false
+		}
+		return wsr.SolarNoon, true
+	}
+	v, ok := wsr.Fields[name]
+	return v, ok
+}
+
+// optional returns the value of an optional variable, if it is known.
+func optional(value *float64) (interface{}, bool) {
+	if value == nil {
+		return nil, false
+	}
+	return *value, true
+}
+
+// coversFields reports whether cached holds every requested variable. The
+// archive does not provide every variable, so past days are not expected to
+// hold those.
+func coversFields(cached *CachedWeather, fields []string, historical bool) bool {
+	for _, name := range fields {
+		if v, _ := LookupVariable(name); historical && !v.Archive {
+			continue
+		}
+		if !cached.has(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// has reports whether the cached item holds the variable name. Items cached
+// before a default variable was added do not hold it.
+func (cached *CachedWeather) has(name string) bool {
+	_, ok := CachedDataToWeatherServiceResponse(*cached).value(name)
+	return ok
+}
+
+// fetchFields returns the variables to fetch for fields, keeping the ones
+// stale cache items already hold so that refreshing them loses nothing.
+func fetchFields(fields []string, stale ...*CachedWeather) []string {
+	var held []string
+	for _, cached := range stale {
+		for name := range cached.Fields {
+			held = append(held, name)
+		}
+	}
+	sort.Strings(held)
+	return MergeVariables(fields, held...)
+}
+
+// plainWeatherServiceResponse has the default encoding of WeatherServiceResponse.
+type plainWeatherServiceResponse WeatherServiceResponse
+
+// MarshalJSON encodes the default fields, or date, location, the selected
+// variables, units and timezone when fields were selected.
+func (wsr WeatherServiceResponse) MarshalJSON() ([]byte, error) {
+	if wsr.selected == nil {
+		return json.Marshal(plainWeatherServiceResponse(wsr))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	err := wsr.eachSelected(func(name string, value interface{}) error {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(encoded)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// MarshalXML is the XML counterpart of MarshalJSON. Variables without a value
+// are left out.
+func (wsr WeatherServiceResponse) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	// Marshal names the element after the type, every response is a forecast.
+	start.Name = xml.Name{Local: "forecast"}
+	if wsr.selected == nil {
+		return e.EncodeElement(plainWeatherServiceResponse(wsr), start)
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	err := wsr.eachSelected(func(name string, value interface{}) error {
+		if value == nil {
+			return nil
+		}
+		return e.EncodeElement(value, xml.StartElement{Name: xml.Name{Local: name}})
+	})
+	if err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+// eachSelected calls fn with the response name and value of every field of
+// a response with selected fields, in encoding order. Missing variables have
+// a nil value.
+func (wsr WeatherServiceResponse) eachSelected(fn func(name string, value interface{}) error) error {
+	for _, field := range []struct {
+		name  string
+		value interface{}
+	}{{"date", wsr.Date}, {"latitude", wsr.Latitude}, {"longitude", wsr.Longitude}} {
+		if err := fn(field.name, field.value); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range wsr.selected {
+		v, _ := LookupVariable(name)
+		value, _ := wsr.value(name)
+		if err := fn(v.Field, value); err != nil {
+			return err
+		}
+		if name == "uv_index_max" && wsr.UVCategory != "" {
+			if err := fn("uvCategory", wsr.UVCategory); err != nil {
+				return err
+			}
+			if err := fn("uvProtection", wsr.UVProtection); err != nil {
+				return err
+			}
+			if wsr.SafeExposure != nil {
+				if err := fn("safeExposure", wsr.SafeExposure); err != nil {
+					return err
+				}
+			}
+		}
+		if name == "wind_direction_10m_dominant" && wsr.WindDirectionCompass != "" {
+			if err := fn("windDirectionCompass", wsr.WindDirectionCompass); err != nil {
+				return err
+			}
+		}
+		if name == "weather_code" && wsr.Condition != "" {
+			if err := fn("condition", wsr.Condition); err != nil {
+				return err
+			}
+			if err := fn("icon", wsr.Icon); err != nil {
+				return err
+			}
+			if err := fn("severity", wsr.Severity); err != nil {
+				return err
+			}
+		}
+	}
+
+	if wsr.Units != nil {
+		if err := fn("units", wsr.Units); err != nil {
+			return err
+		}
+	}
+	return fn("timezone", wsr.Timezone)
+}
@@ -0,0 +1,157 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+	"weather-service/helper/mockutil"
+	"weather-service/internal/handler"
+	"weather-service/internal/handler/mocks"
+)
+
+var _ = Describe("Fields", mockutil.Mockable(func(helper *mockutil.Helper) {
+	var (
+		mockCache          *mocks.MockCache
+		mockForecastClient *mocks.MockForecastClient
+		ws                 *handler.WeatherService
+	)
+
+	today := time.Now().UTC().Format("2006-01-02")
+	uvIndex := 3.0
+	key := fmt.Sprintf("42.00_23.00_%s", today)
+
+	BeforeEach(func() {
+		mockCache = mocks.NewMockCache(helper.Controller())
+		mockForecastClient = mocks.NewMockForecastClient(helper.Controller())
+		ws = handler.NewWeatherService(mockForecastClient, mockCache)
+	})
+
+	When("fields are requested and cached", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{
+				Key:     key,
+				TempMax: 23.5,
+				UVIndex: &uvIndex,
+				Sunrise: today + "T06:58:00Z",
+				Fields:  map[string]interface{}{"temperature_2m_min": 12.5},
+			}, nil).Times(1)
+			mockForecastClient.EXPECT().GetForecast(gomock.Any(), gomock.Any()).Times(0)
+		})
+
+		It("should return only them, in the requested order", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "fields": "sunrise,temperatureMin,uv_index_max"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Body).To(Equal(fmt.Sprintf(`{"date":"%s","latitude":"42.00","longitude":"23.00","sunrise":"%sT06:58:00Z","temperatureMin":12.5,"uvIndex":3,`+moderateUVJSON+
+				`"units":{"temperature":"celsius","precipitation":"mm","windSpeed":"kmh"},"timezone":"UTC"}`, today, today)))
+		})
+
+		It("should convert them to the requested units", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "fields": "temperature_2m_min", "units": "imperial"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Body).To(ContainSubstring(`"temperatureMin":54.5,`))
+		})
+
+		It("should encode them as csv", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "fields": "temperature_2m_min,sunrise", "format": "csv"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Body).To(Equal(fmt.Sprintf("date,latitude,longitude,temperatureMin,sunrise,temperatureUnit,precipitationUnit,windSpeedUnit\n%s,42.00,23.00,12.5,%sT06:58:00Z,celsius,mm,kmh\n", today, today)))
+		})
+
+		It("should encode them as xml", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "fields": "temperature_2m_min", "format": "xml"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Body).To(ContainSubstring(fmt.Sprintf(`<forecast><date>%s</date><latitude>42.00</latitude><longitude>23.00</longitude><temperatureMin>12.5</temperatureMin><units>`, today)))
+		})
+	})
+
+	When("the wind direction is selected", func() {
+		BeforeEach(func() {
+			direction := 200.0
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{Key: key, TempMax: 23.5, WindDirection: &direction}, nil).Times(1)
+		})
+
+		It("should follow it with its compass point", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "fields": "wind_direction_10m_dominant"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Body).To(ContainSubstring(`"longitude":"23.00","windDirection":200,"windDirectionCompass":"SSW","units"`))
+		})
+	})
+
+	When("a cached day is missing a requested field", func() {
+		var fetched *handler.CachedWeather
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{
+				Key:     key,
+				TempMax: 23.5,
+				Fields:  map[string]interface{}{"sunset": today + "T18:40"},
+			}, nil).Times(1)
+			mockForecastClient.EXPECT().GetForecast("42.00", "23.00", "sunshine_duration", "sunset").Return(handler.ForecastMap{
+				today: handler.Forecast{Temp2max: 24, Fields: map[string]interface{}{"sunshine_duration": 14.4, "sunset": today + "T18:40"}},
+			}, nil).Times(1)
+			mockCache.EXPECT().Put(key, gomock.Any()).DoAndReturn(func(_ string, cw *handler.CachedWeather) error {
+				fetched = cw
+				return nil
+			}).Times(1)
+		})
+
+		It("should fetch it along with the fields the cache held", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "fields": "sunshine_duration"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+
+			var body map[string]interface{}
+			Expect(json.Unmarshal([]byte(res.Body), &body)).To(Succeed())
+			Expect(body).To(HaveKeyWithValue("sunshineDuration", 14.4))
+			Expect(body).ToNot(HaveKey("temperature"))
+			Expect(fetched.Fields).To(HaveKeyWithValue("sunset", today+"T18:40"))
+		})
+	})
+
+	When("a requested field is not in the forecast", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(key).Return(nil, nil).Times(1)
+			mockForecastClient.EXPECT().GetForecast("42.00", "23.00", "rain_sum").Return(handler.ForecastMap{
+				today: handler.Forecast{Temp2max: 24},
+			}, nil).Times(1)
+			mockCache.EXPECT().Put(key, gomock.Any()).Return(nil).Times(1)
+		})
+
+		It("should return it as null", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "fields": "rain_sum"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Body).To(ContainSubstring(`"rainSum":null,`))
+		})
+	})
+
+	When("an unknown field is requested", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(gomock.Any()).Times(0)
+		})
+
+		It("should return 400", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "fields": "temperature,humidity"},
+			})
+			Expect(res.StatusCode).To(Equal(400))
+			Expect(res.Body).To(ContainSubstring("Invalid fields: unknown field humidity"))
+			Expect(res.Body).To(ContainSubstring(`"name":"fields"`))
+		})
+	})
+}))
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// DefaultForecastDays is the forecast horizon, today included, when none
+// is configured. It matches the provider's default.
+const DefaultForecastDays = 7
+
+// parseForecastDate parses date and checks that it falls inside the forecast
+// window, which starts at today in loc and spans ForecastDays days. When an
+// archive is configured, dates back to the start of the archive are accepted too.
+func (wsvc *WeatherService) parseForecastDate(date string, loc *time.Location) (time.Time, error) {
+	parsedDate, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid date")
+	}
+
+	today, _ := time.Parse(dateLayout, todayDate(loc))
+	if parsedDate.Before(today) && wsvc.ArchiveClient == nil {
+		return time.Time{}, fmt.Errorf("Invalid date: Date could not be older than today")
+	}
+
+	if date < archiveStartDate {
+		return time.Time{}, fmt.Errorf("Invalid date: Date could not be before %s", archiveStartDate)
+	}
+
+	if !parsedDate.Before(today.AddDate(0, 0, wsvc.forecastDays())) {
+		return time.Time{}, fmt.Errorf("Invalid date: Date could not be %d day from today", wsvc.forecastDays())
+	}
+
+	return parsedDate, nil
+}
+
+// forecastDays returns the configured horizon, falling back to the default
+// when it is unset. The configuration bounds it by what the provider supports.
+func (wsvc *WeatherService) forecastDays() int {
+	if wsvc.ForecastDays < 1 {
+		return DefaultForecastDays
+	}
+	return wsvc.ForecastDays
+}
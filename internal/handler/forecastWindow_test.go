@@ -0,0 +1,93 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"strings"
+	"time"
+	"weather-service/helper/mockutil"
+	"weather-service/internal/handler"
+	"weather-service/internal/handler/mocks"
+)
+
+var _ = Describe("ForecastWindow", mockutil.Mockable(func(helper *mockutil.Helper) {
+	var (
+		mockCache          *mocks.MockCache
+		mockForecastClient *mocks.MockForecastClient
+		ws                 *handler.WeatherService
+	)
+
+	day := func(offset int) string {
+		return time.Now().UTC().AddDate(0, 0, offset).Format("2006-01-02")
+	}
+
+	BeforeEach(func() {
+		mockCache = mocks.NewMockCache(helper.Controller())
+		mockForecastClient = mocks.NewMockForecastClient(helper.Controller())
+		ws = handler.NewWeatherService(mockForecastClient, mockCache)
+	})
+
+	When("the default horizon is used", func() {
+		It("should reject the day after the last forecast day", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": day(7)},
+			})
+			Expect(res.StatusCode).To(Equal(400))
+			Expect(res.Body).To(ContainSubstring("Date could not be 7 day from today"))
+		})
+	})
+
+	Context("16 forecast days are configured", func() {
+		BeforeEach(func() {
+			ws.ForecastDays = 16
+		})
+
+		When("the last forecast day is requested", func() {
+			BeforeEach(func() {
+				key := fmt.Sprintf("42.00_23.00_%s", day(15))
+				mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{Key: key, TempMax: 23}, nil).Times(1)
+			})
+
+			It("should return it", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": day(15)},
+				})
+				Expect(res.StatusCode).To(Equal(200))
+			})
+		})
+
+		When("a date past the horizon is requested", func() {
+			It("should mention the configured horizon", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "days": "17"},
+				})
+				Expect(res.StatusCode).To(Equal(400))
+				Expect(res.Body).To(ContainSubstring("Date could not be 16 day from today"))
+			})
+		})
+
+		When("the calendar is requested", func() {
+			BeforeEach(func() {
+				fm := handler.ForecastMap{}
+				for i := 0; i < 16; i++ {
+					fm[day(i)] = handler.Forecast{Temp2max: 20}
+				}
+				mockCache.EXPECT().Get(gomock.Any()).Return(nil, nil).Times(16)
+				mockForecastClient.EXPECT().GetForecast("42.00", "23.00").Return(fm, nil).Times(1)
+				mockCache.EXPECT().Put(gomock.Any(), gomock.Any()).Return(nil).Times(16)
+			})
+
+			It("should cover the whole horizon", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					Path:            "/weather/calendar",
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0"},
+				})
+				Expect(res.StatusCode).To(Equal(200))
+				Expect(strings.Count(res.Body, "BEGIN:VEVENT")).To(Equal(16))
+			})
+		})
+	})
+}))
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Routes maps the paths ServeHTTP answers to their methods. GET routes also
+// answer HEAD.
+var Routes = map[string][]string{
+	"/weather":           {http.MethodGet},
+	"/weather/hourly":    {http.MethodGet},
+	"/weather/batch":     {http.MethodPost},
+	"/weather/calendar":  {http.MethodGet},
+	"/weather/chart.svg": {http.MethodGet},
+}
+
+// ServeHTTP adapts plain net/http requests to Handle, so the service can run
+// outside of Lambda.
+func (wsvc *WeatherService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := make(map[string]string, len(r.URL.Query()))
+	for name, values := range r.URL.Query() {
+		query[name] = values[0]
+	}
+
+	headers := make(map[string]string, len(r.Header))
+	for name, values := range r.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeResponse(w, errorResponse(&serviceError{StatusCode: http.StatusBadRequest, Code: CodeInvalidBody, Message: "Could not read request body"}))
+		return
+	}
+
+	writeResponse(w, wsvc.Handle(r.Context(), Request{
+		Method:          r.Method,
+		Path:            r.URL.Path,
+		QueryParameters: query,
+		Headers:         headers,
+		Body:            string(body),
+	}))
+}
+
+// Unrouted answers requests none of Routes match with a problem: 405 with an
+// Allow header when only the method is wrong, 404 otherwise.
+func Unrouted(w http.ResponseWriter, r *http.Request) {
+	methods, ok := Routes[r.URL.Path]
+	if !ok {
+		writeResponse(w, errorResponse(&serviceError{StatusCode: http.StatusNotFound, Code: CodeNotFound, Message: fmt.Sprintf("No such path: %s", r.URL.Path)}))
+		return
+	}
+
+	var allow []string
+	for _, method := range methods {
+		allow = append(allow, method)
+		if method == http.MethodGet {
+			allow = append(allow, http.MethodHead)
+		}
+	}
+	writeResponse(w, methodNotAllowedResponse(fmt.Sprintf("%s only answers %s", r.URL.Path, strings.Join(allow, ", ")), allow...))
+}
+
+func writeResponse(w http.ResponseWriter, res Response) {
+	for name, value := range res.Headers {
+		w.Header().Set(name, value)
+	}
+	w.WriteHeader(res.StatusCode)
+	_, _ = w.Write([]byte(res.Body))
+}
@@ -0,0 +1,91 @@
+package handler_test
+
+import (
+	"fmt"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"time"
+	"weather-service/helper/mockutil"
+	"weather-service/internal/handler"
+	"weather-service/internal/handler/mocks"
+)
+
+var _ = Describe("HTTP", mockutil.Mockable(func(helper *mockutil.Helper) {
+	var (
+		mockCache          *mocks.MockCache
+		mockForecastClient *mocks.MockForecastClient
+		ws                 *handler.WeatherService
+	)
+
+	today := time.Now().UTC().Format("2006-01-02")
+	uvIndex, rainProbability := 3.0, 0.0
+
+	BeforeEach(func() {
+		mockCache = mocks.NewMockCache(helper.Controller())
+		mockForecastClient = mocks.NewMockForecastClient(helper.Controller())
+		ws = handler.NewWeatherService(mockForecastClient, mockCache)
+	})
+
+	When("cache return data", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(fmt.Sprintf("42.00_23.00_%s", today)).Return(&handler.CachedWeather{
+				Key:      fmt.Sprintf("42.00_23.00_%s", today),
+				TempMax:  23,
+				UVIndex:  &uvIndex,
+				RainProb: &rainProbability,
+			}, nil).Times(1)
+			mockForecastClient.EXPECT().GetForecast(gomock.Any(), gomock.Any()).Times(0)
+		})
+
+		It("should write the weather response", func() {
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/weather?lat=42.0&lon=23.0&date=%s", today), nil)
+			rec := httptest.NewRecorder()
+
+			ws.ServeHTTP(rec, req)
+
+			Expect(rec.Code).To(Equal(200))
+			Expect(rec.Header().Get("Content-Type")).To(Equal("application/json"))
+			Expect(rec.Body.String()).To(Equal(fmt.Sprintf("{\"date\":\"%s\",\"latitude\":\"42.00\",\"longitude\":\"23.00\",\"temperature\":23,\"uvIndex\":3,"+moderateUVJSON+"\"rainProbability\":0,\"summary\":\"High of 23°C, low chance of rain, moderate UV\",\"units\":{\"temperature\":\"celsius\",\"precipitation\":\"mm\",\"windSpeed\":\"kmh\"},\"timezone\":\"UTC\"}", today)))
+		})
+	})
+
+	When("latitude or longitude is not provided", func() {
+		It("should write error response", func() {
+			req := httptest.NewRequest(http.MethodGet, "/weather?lon=23.0", nil)
+			rec := httptest.NewRecorder()
+
+			ws.ServeHTTP(rec, req)
+
+			Expect(rec.Code).To(Equal(400))
+			Expect(rec.Body.String()).To(ContainSubstring("Missing lat/lon"))
+		})
+	})
+
+	When("the path is unknown", func() {
+		It("should write a not found problem", func() {
+			rec := httptest.NewRecorder()
+
+			handler.Unrouted(rec, httptest.NewRequest(http.MethodGet, "/forecast", nil))
+
+			Expect(rec.Code).To(Equal(404))
+			Expect(rec.Header().Get("Content-Type")).To(Equal("application/problem+json"))
+			Expect(rec.Body.String()).To(ContainSubstring(`"code":"not-found"`))
+		})
+	})
+
+	When("the method is not allowed", func() {
+		It("should write a method not allowed problem with the allowed methods", func() {
+			rec := httptest.NewRecorder()
+
+			handler.Unrouted(rec, httptest.NewRequest(http.MethodPost, "/weather", nil))
+
+			Expect(rec.Code).To(Equal(405))
+			Expect(rec.Header().Get("Content-Type")).To(Equal("application/problem+json"))
+			Expect(rec.Header().Get("Allow")).To(Equal("GET, HEAD"))
+			Expect(rec.Body.String()).To(ContainSubstring(`"code":"method-not-allowed"`))
+		})
+	})
+}))
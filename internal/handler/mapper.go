@@ -2,27 +2,163 @@ package handler
 
 import (
 	"strings"
+	"weather-service/internal/wind"
 )
 
 func CachedDataToWeatherServiceResponse(cachedData CachedWeather) WeatherServiceResponse {
 	//key = lat_lon_date
 	keySplit := strings.Split(cachedData.Key, "_")
 
+	// items cached before these variables had attributes of their own hold
+	// them in Fields, if they were requested
+	legacy := func(value *float64, name string) *float64 {
+		if value != nil {
+			return value
+		}
+		if v, ok := cachedData.Fields[name].(float64); ok {
+			return &v
+		}
+		return nil
+	}
+
+	wsr := WeatherServiceResponse{
+		Date:                   keySplit[2],
+		Latitude:               keySplit[0],
+		Longitude:              keySplit[1],
+		Temperature:            cachedData.TempMax,
+		TemperatureMin:         legacy(cachedData.TempMin, "temperature_2m_min"),
+		ApparentTemperatureMax: legacy(cachedData.ApparentTempMax, "apparent_temperature_max"),
+		ApparentTemperatureMin: legacy(cachedData.ApparentTempMin, "apparent_temperature_min"),
+		UVIndex:                cachedData.UVIndex,
+		RainProbability:        cachedData.RainProb,
+		PrecipitationSum:       legacy(cachedData.PrecipSum, "precipitation_sum"),
+		RainSum:                legacy(cachedData.RainSum, "rain_sum"),
+		SnowfallSum:            legacy(cachedData.SnowfallSum, "snowfall_sum"),
+		PrecipitationHours:     legacy(cachedData.PrecipHours, "precipitation_hours"),
+		WindSpeedMax:           legacy(cachedData.WindSpeedMax, "wind_speed_10m_max"),
+		WindGustsMax:           legacy(cachedData.WindGustsMax, "wind_gusts_10m_max"),
+		WindDirection:          legacy(cachedData.WindDirection, "wind_direction_10m_dominant"),
+		ConditionCode:          cachedData.ConditionCode,
+		Sunrise:                cachedData.Sunrise,
+		Sunset:                 cachedData.Sunset,
+		SolarNoon:              cachedData.SolarNoon,
+		DaylightDuration:       cachedData.DaylightDuration,
+		Fields:                 cachedData.Fields,
+		Timezone:               cachedData.Timezone,
+		expiresAt:              cachedData.TTL,
+	}
+	wsr.WindDirectionCompass = compass(wsr.WindDirection)
+	return wsr
+}
+
+func ForecastToWeatherServiceResponse(date string, forecast Forecast) WeatherServiceResponse {
 	wsr := WeatherServiceResponse{
-		keySplit[2],
-		keySplit[0],
-		keySplit[1],
-		cachedData.TempMax,
-		cachedData.UVIndex,
-		cachedData.RainProb,
+		Date:                   date,
+		Latitude:               forecast.Latitude,
+		Longitude:              forecast.Longitude,
+		Temperature:            forecast.Temp2max,
+		TemperatureMin:         forecast.Temp2min,
+		ApparentTemperatureMax: forecast.ApparentTempMax,
+		ApparentTemperatureMin: forecast.ApparentTempMin,
+		UVIndex:                forecast.UvIndexMax,
+		RainProbability:        forecast.PrecipProbability,
+		PrecipitationSum:       forecast.PrecipSum,
+		RainSum:                forecast.RainSum,
+		SnowfallSum:            forecast.SnowfallSum,
+		PrecipitationHours:     forecast.PrecipHours,
+		WindSpeedMax:           forecast.WindSpeedMax,
+		WindGustsMax:           forecast.WindGustsMax,
+		WindDirection:          forecast.WindDirection,
+		ConditionCode:          forecast.WeatherCode,
+		Sunrise:                forecast.Sunrise,
+		Sunset:                 forecast.Sunset,
+		SolarNoon:              forecast.SolarNoon,
+		DaylightDuration:       forecast.DaylightDuration,
+		Fields:                 forecast.Fields,
+		Timezone:               forecast.Timezone,
 	}
+	wsr.WindDirectionCompass = compass(wsr.WindDirection)
 	return wsr
 }
 
+// compass returns the compass point of a wind direction, "" when it is unknown.
+func compass(direction *float64) string {
+	if direction == nil {
+		return ""
+	}
+	return wind.Compass(*direction)
+}
+
 func ForecastToCachedData(forecast Forecast) *CachedWeather {
 	return &CachedWeather{
-		TempMax:  forecast.Temp2max,
-		UVIndex:  forecast.UvIndexMax,
-		RainProb: forecast.PrecipProbability,
+		TempMax:          forecast.Temp2max,
+		UVIndex:          forecast.UvIndexMax,
+		RainProb:         forecast.PrecipProbability,
+		TempMin:          forecast.Temp2min,
+		ApparentTempMax:  forecast.ApparentTempMax,
+		ApparentTempMin:  forecast.ApparentTempMin,
+		PrecipSum:        forecast.PrecipSum,
+		RainSum:          forecast.RainSum,
+		SnowfallSum:      forecast.SnowfallSum,
+		PrecipHours:      forecast.PrecipHours,
+		WindSpeedMax:     forecast.WindSpeedMax,
+		WindGustsMax:     forecast.WindGustsMax,
+		WindDirection:    forecast.WindDirection,
+		ConditionCode:    forecast.WeatherCode,
+		Sunrise:          forecast.Sunrise,
+		Sunset:           forecast.Sunset,
+		SolarNoon:        forecast.SolarNoon,
+		DaylightDuration: forecast.DaylightDuration,
+		Fields:           forecast.Fields,
+		Timezone:         forecast.Timezone,
+	}
+}
+
+func CachedHourlyDataToHourlyWeatherServiceResponse(cachedData CachedHourlyWeather) HourlyWeatherServiceResponse {
+	//key = lat_lon_date_hourly
+	keySplit := strings.Split(cachedData.Key, "_")
+
+	hours := make([]HourlyWeather, 0, len(cachedData.Hours))
+	for _, hour := range cachedData.Hours {
+		hours = append(hours, HourlyWeather{
+			Time:            hour.Time,
+			Temperature:     hour.Temp,
+			RainProbability: hour.RainProb,
+			Precipitation:   hour.Precipitation,
+			WindSpeed:       hour.WindSpeed,
+			CloudCover:      hour.CloudCover,
+		})
+	}
+
+	return HourlyWeatherServiceResponse{
+		Date:      keySplit[2],
+		Latitude:  keySplit[0],
+		Longitude: keySplit[1],
+		Hours:     hours,
+		Timezone:  cachedData.Timezone,
+		expiresAt: cachedData.TTL,
+	}
+}
+
+func HourlyForecastsToCachedData(forecasts []HourlyForecast) *CachedHourlyWeather {
+	hours := make([]CachedHour, 0, len(forecasts))
+	for _, forecast := range forecasts {
+		hours = append(hours, CachedHour{
+			Time:          forecast.Time,
+			Temp:          forecast.Temp2m,
+			RainProb:      forecast.PrecipProbability,
+			Precipitation: forecast.Precipitation,
+			WindSpeed:     forecast.WindSpeed10m,
+			CloudCover:    forecast.CloudCover,
+		})
+	}
+
+	var timezone string
+	if len(forecasts) > 0 {
+		timezone = forecasts[0].Timezone
+	}
+	return &CachedHourlyWeather{
+		Hours:    hours,
+		Timezone: timezone,
 	}
 }
@@ -0,0 +1,55 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: archive.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+	handler "weather-service/internal/handler"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockArchiveClient is a mock of ArchiveClient interface.
+type MockArchiveClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockArchiveClientMockRecorder
+}
+
+// MockArchiveClientMockRecorder is the mock recorder for MockArchiveClient.
+type MockArchiveClientMockRecorder struct {
+	mock *MockArchiveClient
+}
+
+// NewMockArchiveClient creates a new mock instance.
+func NewMockArchiveClient(ctrl *gomock.Controller) *MockArchiveClient {
+	mock := &MockArchiveClient{ctrl: ctrl}
+	mock.recorder = &MockArchiveClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArchiveClient) EXPECT() *MockArchiveClientMockRecorder {
+	return m.recorder
+}
+
+// GetArchive mocks base method.
+func (m *MockArchiveClient) GetArchive(lat, long, start, end string, fields ...string) (handler.ForecastMap, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{lat, long, start, end}
+	for _, a := range fields {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetArchive", varargs...)
+	ret0, _ := ret[0].(handler.ForecastMap)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetArchive indicates an expected call of GetArchive.
+func (mr *MockArchiveClientMockRecorder) GetArchive(lat, long, start, end interface{}, fields ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{lat, long, start, end}, fields...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArchive", reflect.TypeOf((*MockArchiveClient)(nil).GetArchive), varargs...)
+}
@@ -0,0 +1,48 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: timezone.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTimezoneLocator is a mock of TimezoneLocator interface.
+type MockTimezoneLocator struct {
+	ctrl     *gomock.Controller
+	recorder *MockTimezoneLocatorMockRecorder
+}
+
+// MockTimezoneLocatorMockRecorder is the mock recorder for MockTimezoneLocator.
+type MockTimezoneLocatorMockRecorder struct {
+	mock *MockTimezoneLocator
+}
+
+// NewMockTimezoneLocator creates a new mock instance.
+func NewMockTimezoneLocator(ctrl *gomock.Controller) *MockTimezoneLocator {
+	mock := &MockTimezoneLocator{ctrl: ctrl}
+	mock.recorder = &MockTimezoneLocatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTimezoneLocator) EXPECT() *MockTimezoneLocatorMockRecorder {
+	return m.recorder
+}
+
+// Timezone mocks base method.
+func (m *MockTimezoneLocator) Timezone(lat, lon string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Timezone", lat, lon)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Timezone indicates an expected call of Timezone.
+func (mr *MockTimezoneLocatorMockRecorder) Timezone(lat, lon interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Timezone", reflect.TypeOf((*MockTimezoneLocator)(nil).Timezone), lat, lon)
+}
@@ -35,18 +35,58 @@ func (m *MockForecastClient) EXPECT() *MockForecastClientMockRecorder {
 }
 
 // GetForecast mocks base method.
-func (m *MockForecastClient) GetForecast(lat, long string) (handler.ForecastMap, error) {
+func (m *MockForecastClient) GetForecast(lat, long string, fields ...string) (handler.ForecastMap, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetForecast", lat, long)
+	varargs := []interface{}{lat, long}
+	for _, a := range fields {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetForecast", varargs...)
 	ret0, _ := ret[0].(handler.ForecastMap)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetForecast indicates an expected call of GetForecast.
-func (mr *MockForecastClientMockRecorder) GetForecast(lat, long interface{}) *gomock.Call {
+func (mr *MockForecastClientMockRecorder) GetForecast(lat, long interface{}, fields ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForecast", reflect.TypeOf((*MockForecastClient)(nil).GetForecast), lat, long)
+	varargs := append([]interface{}{lat, long}, fields...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForecast", reflect.TypeOf((*MockForecastClient)(nil).GetForecast), varargs...)
+}
+
+// GetForecasts mocks base method.
+func (m *MockForecastClient) GetForecasts(locations []handler.Location, fields ...string) ([]handler.ForecastMap, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{locations}
+	for _, a := range fields {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetForecasts", varargs...)
+	ret0, _ := ret[0].([]handler.ForecastMap)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetForecasts indicates an expected call of GetForecasts.
+func (mr *MockForecastClientMockRecorder) GetForecasts(locations interface{}, fields ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{locations}, fields...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForecasts", reflect.TypeOf((*MockForecastClient)(nil).GetForecasts), varargs...)
+}
+
+// GetHourlyForecast mocks base method.
+func (m *MockForecastClient) GetHourlyForecast(lat, long string) (handler.HourlyForecastMap, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHourlyForecast", lat, long)
+	ret0, _ := ret[0].(handler.HourlyForecastMap)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHourlyForecast indicates an expected call of GetHourlyForecast.
+func (mr *MockForecastClientMockRecorder) GetHourlyForecast(lat, long interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHourlyForecast", reflect.TypeOf((*MockForecastClient)(nil).GetHourlyForecast), lat, long)
 }
 
 // MockCache is a mock of Cache interface.
@@ -87,6 +127,21 @@ func (mr *MockCacheMockRecorder) Get(key interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCache)(nil).Get), key)
 }
 
+// GetHourly mocks base method.
+func (m *MockCache) GetHourly(key string) (*handler.CachedHourlyWeather, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHourly", key)
+	ret0, _ := ret[0].(*handler.CachedHourlyWeather)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHourly indicates an expected call of GetHourly.
+func (mr *MockCacheMockRecorder) GetHourly(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHourly", reflect.TypeOf((*MockCache)(nil).GetHourly), key)
+}
+
 // Put mocks base method.
 func (m *MockCache) Put(key string, weather *handler.CachedWeather) error {
 	m.ctrl.T.Helper()
@@ -100,3 +155,68 @@ func (mr *MockCacheMockRecorder) Put(key, weather interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockCache)(nil).Put), key, weather)
 }
+
+// PutHourly mocks base method.
+func (m *MockCache) PutHourly(key string, weather *handler.CachedHourlyWeather) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutHourly", key, weather)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutHourly indicates an expected call of PutHourly.
+func (mr *MockCacheMockRecorder) PutHourly(key, weather interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutHourly", reflect.TypeOf((*MockCache)(nil).PutHourly), key, weather)
+}
+
+// PutPermanent mocks base method.
+func (m *MockCache) PutPermanent(key string, weather *handler.CachedWeather) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutPermanent", key, weather)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutPermanent indicates an expected call of PutPermanent.
+func (mr *MockCacheMockRecorder) PutPermanent(key, weather interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutPermanent", reflect.TypeOf((*MockCache)(nil).PutPermanent), key, weather)
+}
+
+// MockGeocoder is a mock of Geocoder interface.
+type MockGeocoder struct {
+	ctrl     *gomock.Controller
+	recorder *MockGeocoderMockRecorder
+}
+
+// MockGeocoderMockRecorder is the mock recorder for MockGeocoder.
+type MockGeocoderMockRecorder struct {
+	mock *MockGeocoder
+}
+
+// NewMockGeocoder creates a new mock instance.
+func NewMockGeocoder(ctrl *gomock.Controller) *MockGeocoder {
+	mock := &MockGeocoder{ctrl: ctrl}
+	mock.recorder = &MockGeocoderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGeocoder) EXPECT() *MockGeocoderMockRecorder {
+	return m.recorder
+}
+
+// Lookup mocks base method.
+func (m *MockGeocoder) Lookup(name, country string) []handler.Place {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lookup", name, country)
+	ret0, _ := ret[0].([]handler.Place)
+	return ret0
+}
+
+// Lookup indicates an expected call of Lookup.
+func (mr *MockGeocoderMockRecorder) Lookup(name, country interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lookup", reflect.TypeOf((*MockGeocoder)(nil).Lookup), name, country)
+}
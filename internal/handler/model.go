@@ -1,28 +1,240 @@
 package handler
 
+import (
+	"encoding/json"
+	"encoding/xml"
+)
+
 type WeatherServiceResponse struct {
-	Date            string  `json:"date"`
-	Latitude        string  `json:"latitude"`
-	Longitude       string  `json:"longitude"`
+	XMLName     xml.Name `json:"-" xml:"forecast"`
+	Date        string   `json:"date" xml:"date"`
+	Latitude    string   `json:"latitude" xml:"latitude"`
+	Longitude   string   `json:"longitude" xml:"longitude"`
+	Temperature float64  `json:"temperature" xml:"temperature"`
+	// TemperatureMin and the apparent temperatures are left out, like the
+	// precipitation amounts, for items cached before they were fetched.
+	TemperatureMin         *float64 `json:"temperatureMin,omitempty" xml:"temperatureMin,omitempty"`
+	ApparentTemperatureMax *float64 `json:"apparentTemperatureMax,omitempty" xml:"apparentTemperatureMax,omitempty"`
+	ApparentTemperatureMin *float64 `json:"apparentTemperatureMin,omitempty" xml:"apparentTemperatureMin,omitempty"`
+	// UVIndex is left out for past days, the archive has none.
+	UVIndex *float64 `json:"uvIndex,omitempty" xml:"uvIndex,omitempty"`
+	// UVCategory, UVProtection and SafeExposure interpret UVIndex, they are
+	// left out with it.
+	UVCategory   string         `json:"uvCategory,omitempty" xml:"uvCategory,omitempty"`
+	UVProtection []string       `json:"uvProtection,omitempty" xml:"uvProtection>measure,omitempty"`
+	SafeExposure []SafeExposure `json:"safeExposure,omitempty" xml:"safeExposure>skinType,omitempty"`
+	// RainProbability is left out for past days, the archive has none.
+	RainProbability *float64 `json:"rainProbability,omitempty" xml:"rainProbability,omitempty"`
+	// PrecipitationSum and RainSum are in Units.Precipitation, SnowfallSum in
+	// cm or inch, PrecipitationHours in hours.
+	PrecipitationSum   *float64 `json:"precipitationSum,omitempty" xml:"precipitationSum,omitempty"`
+	RainSum            *float64 `json:"rainSum,omitempty" xml:"rainSum,omitempty"`
+	SnowfallSum        *float64 `json:"snowfallSum,omitempty" xml:"snowfallSum,omitempty"`
+	PrecipitationHours *float64 `json:"precipitationHours,omitempty" xml:"precipitationHours,omitempty"`
+	// WindSpeedMax and WindGustsMax are in Units.WindSpeed, WindDirection is
+	// the dominant direction in degrees and WindDirectionCompass its 16-point
+	// compass label.
+	WindSpeedMax         *float64 `json:"windSpeedMax,omitempty" xml:"windSpeedMax,omitempty"`
+	WindGustsMax         *float64 `json:"windGustsMax,omitempty" xml:"windGustsMax,omitempty"`
+	WindDirection        *float64 `json:"windDirection,omitempty" xml:"windDirection,omitempty"`
+	WindDirectionCompass string   `json:"windDirectionCompass,omitempty" xml:"windDirectionCompass,omitempty"`
+	// Condition, Icon and Severity describe ConditionCode, the WMO weather
+	// code of the day, in the requested language. Icon is the night icon
+	// after sunset of the current day. They are left out when the code is
+	// unknown.
+	Condition     string `json:"condition,omitempty" xml:"condition,omitempty"`
+	ConditionCode *int   `json:"conditionCode,omitempty" xml:"conditionCode,omitempty"`
+	Icon          string `json:"icon,omitempty" xml:"icon,omitempty"`
+	Severity      string `json:"severity,omitempty" xml:"severity,omitempty"`
+	// Sunrise, Sunset and SolarNoon are ISO 8601 times with the offset of
+	// Timezone. Sunrise and Sunset are left out on polar days and nights.
+	Sunrise   string `json:"sunrise,omitempty" xml:"sunrise,omitempty"`
+	Sunset    string `json:"sunset,omitempty" xml:"sunset,omitempty"`
+	SolarNoon string `json:"solarNoon,omitempty" xml:"solarNoon,omitempty"`
+	// DaylightDuration is in seconds.
+	DaylightDuration *float64 `json:"daylightDuration,omitempty" xml:"daylightDuration,omitempty"`
+	// Summary is a one-line description of the day in the requested language.
+	Summary string `json:"summary,omitempty" xml:"summary,omitempty"`
+	Units   *Units `json:"units,omitempty" xml:"units,omitempty"`
+	// Timezone is the IANA timezone the date was resolved in.
+	Timezone string `json:"timezone" xml:"timezone"`
+	// Fields holds the values of the variables without a dedicated field,
+	// keyed by Open-Meteo name. They are only encoded when selected.
+	Fields map[string]interface{} `json:"-" xml:"-"`
+	// selected lists the variables requested with fields=, nil for the default fields.
+	selected []string
+	// expiresAt is the unix time the underlying cache item expires at, 0 when unknown.
+	expiresAt int64
+}
+
+// SafeExposure is the estimated time skin of a Fitzpatrick skin type can be
+// exposed to the day's maximum UV index before it reddens.
+type SafeExposure struct {
+	SkinType string `json:"skinType" xml:"type,attr"`
+	Minutes  int    `json:"minutes" xml:"minutes,attr"`
+}
+
+// Request is the transport-agnostic view of an incoming API call. The
+// API Gateway adapters translate their event shapes into it.
+type Request struct {
+	Method          string
+	Path            string
+	QueryParameters map[string]string
+	Headers         map[string]string
+	Body            string
+}
+
+// Response is the transport-agnostic result of handling a Request.
+type Response struct {
+	StatusCode int
+	Body       string
+	Headers    map[string]string
+}
+
+type BatchItem struct {
+	Lat  json.Number `json:"lat"`
+	Lon  json.Number `json:"lon"`
+	Date string      `json:"date"`
+}
+
+type BatchItemResult struct {
+	Lat     string                  `json:"lat"`
+	Lon     string                  `json:"lon"`
+	Date    string                  `json:"date,omitempty"`
+	Weather *WeatherServiceResponse `json:"weather,omitempty"`
+	Error   *Problem                `json:"error,omitempty"`
+}
+
+type HourlyWeatherServiceResponse struct {
+	Date      string          `json:"date"`
+	Latitude  string          `json:"latitude"`
+	Longitude string          `json:"longitude"`
+	Hours     []HourlyWeather `json:"hours"`
+	Units     *Units          `json:"units,omitempty"`
+	// Timezone is the IANA timezone the date and hours are in.
+	Timezone string `json:"timezone"`
+	// expiresAt is the unix time the underlying cache item expires at, 0 when unknown.
+	expiresAt int64
+}
+
+type HourlyWeather struct {
+	Time            string  `json:"time"`
 	Temperature     float64 `json:"temperature"`
-	UVIndex         float64 `json:"uvIndex"`
 	RainProbability float64 `json:"rainProbability"`
+	Precipitation   float64 `json:"precipitation"`
+	WindSpeed       float64 `json:"windSpeed"`
+	CloudCover      float64 `json:"cloudCover"`
 }
 
 type Forecast struct {
-	Longitude         string  `json:"longitude"`
-	Latitude          string  `json:"latitude"`
-	Temp2max          float64 `json:"temperature_2m_max"`
-	UvIndexMax        float64 `json:"uv_index_max"`
-	PrecipProbability float64 `json:"precipitation_probability_max"`
+	Longitude string `json:"longitude"`
+	Latitude  string `json:"latitude"`
+	// Timezone is the IANA timezone the provider resolved for the location,
+	// empty when it did not report one.
+	Timezone          string   `json:"timezone,omitempty"`
+	Temp2max          float64  `json:"temperature_2m_max"`
+	UvIndexMax        *float64 `json:"uv_index_max,omitempty"`
+	PrecipProbability *float64 `json:"precipitation_probability_max,omitempty"`
+	// The values below are nil when the provider has none.
+	Temp2min        *float64 `json:"temperature_2m_min,omitempty"`
+	ApparentTempMax *float64 `json:"apparent_temperature_max,omitempty"`
+	ApparentTempMin *float64 `json:"apparent_temperature_min,omitempty"`
+	PrecipSum       *float64 `json:"precipitation_sum,omitempty"`
+	RainSum         *float64 `json:"rain_sum,omitempty"`
+	SnowfallSum     *float64 `json:"snowfall_sum,omitempty"`
+	PrecipHours     *float64 `json:"precipitation_hours,omitempty"`
+	WindSpeedMax    *float64 `json:"wind_speed_10m_max,omitempty"`
+	WindGustsMax    *float64 `json:"wind_gusts_10m_max,omitempty"`
+	WindDirection   *float64 `json:"wind_direction_10m_dominant,omitempty"`
+	// WeatherCode is the WMO weather code, nil when the provider has none.
+	WeatherCode *int `json:"weather_code,omitempty"`
+	// Sunrise and Sunset are local times as reported by the provider until
+	// withSunTimes turns them into ISO 8601 times with an offset.
+	Sunrise   string `json:"sunrise,omitempty"`
+	Sunset    string `json:"sunset,omitempty"`
+	SolarNoon string `json:"solar_noon,omitempty"`
+	// DaylightDuration is in seconds, nil when the provider has none.
+	DaylightDuration *float64 `json:"daylight_duration,omitempty"`
+	// Fields holds the other requested variables, keyed by Open-Meteo name.
+	Fields map[string]interface{} `json:"fields,omitempty"`
 }
 
 type ForecastMap map[string]Forecast
 
+type Place struct {
+	Name       string `json:"name"`
+	Country    string `json:"country"`
+	Latitude   string `json:"latitude"`
+	Longitude  string `json:"longitude"`
+	Population int64  `json:"population"`
+}
+
+type Location struct {
+	Lat string
+	Lon string
+}
+
 type CachedWeather struct {
-	Key      string  `dynamodbav:"Key"`
-	TempMax  float64 `dynamodbav:"TempMax"`
-	UVIndex  float64 `dynamodbav:"UVIndex"`
-	RainProb float64 `dynamodbav:"RainProb"`
-	TTL      int64   `dynamodbav:"TTL"`
+	Key      string   `dynamodbav:"Key"`
+	TempMax  float64  `dynamodbav:"TempMax"`
+	UVIndex  *float64 `dynamodbav:"UVIndex,omitempty"`
+	RainProb *float64 `dynamodbav:"RainProb,omitempty"`
+	// The values below are nil for items cached before they were fetched,
+	// see CachedDataToWeatherServiceResponse.
+	TempMin         *float64 `dynamodbav:"TempMin,omitempty"`
+	ApparentTempMax *float64 `dynamodbav:"ApparentTempMax,omitempty"`
+	ApparentTempMin *float64 `dynamodbav:"ApparentTempMin,omitempty"`
+	PrecipSum       *float64 `dynamodbav:"PrecipSum,omitempty"`
+	RainSum         *float64 `dynamodbav:"RainSum,omitempty"`
+	SnowfallSum     *float64 `dynamodbav:"SnowfallSum,omitempty"`
+	PrecipHours     *float64 `dynamodbav:"PrecipHours,omitempty"`
+	WindSpeedMax    *float64 `dynamodbav:"WindSpeedMax,omitempty"`
+	WindGustsMax    *float64 `dynamodbav:"WindGustsMax,omitempty"`
+	WindDirection   *float64 `dynamodbav:"WindDirection,omitempty"`
+	// ConditionCode is nil for items cached before weather codes were fetched.
+	// The condition is described per request, in the requested language.
+	ConditionCode *int `dynamodbav:"ConditionCode,omitempty"`
+	// Sun times are empty for items cached before they were fetched.
+	Sunrise          string   `dynamodbav:"Sunrise,omitempty"`
+	Sunset           string   `dynamodbav:"Sunset,omitempty"`
+	SolarNoon        string   `dynamodbav:"SolarNoon,omitempty"`
+	DaylightDuration *float64 `dynamodbav:"DaylightDuration,omitempty"`
+	// Fields holds the variables fetched besides the default ones.
+	Fields map[string]interface{} `dynamodbav:"Fields,omitempty"`
+	// Timezone is the location's timezone as the provider reported it, empty
+	// for items cached before it was kept.
+	Timezone string `dynamodbav:"Timezone,omitempty"`
+	// TTL is 0 for items that never expire.
+	TTL int64 `dynamodbav:"TTL,omitempty"`
+}
+
+type HourlyForecast struct {
+	Longitude         string  `json:"longitude"`
+	Latitude          string  `json:"latitude"`
+	Timezone          string  `json:"timezone,omitempty"`
+	Time              string  `json:"time"`
+	Temp2m            float64 `json:"temperature_2m"`
+	PrecipProbability float64 `json:"precipitation_probability"`
+	Precipitation     float64 `json:"precipitation"`
+	WindSpeed10m      float64 `json:"wind_speed_10m"`
+	CloudCover        float64 `json:"cloud_cover"`
+}
+
+// HourlyForecastMap groups hourly forecasts by their date.
+type HourlyForecastMap map[string][]HourlyForecast
+
+type CachedHourlyWeather struct {
+	Key      string       `dynamodbav:"Key"`
+	Hours    []CachedHour `dynamodbav:"Hours"`
+	Timezone string       `dynamodbav:"Timezone,omitempty"`
+	TTL      int64        `dynamodbav:"TTL"`
+}
+
+type CachedHour struct {
+	Time          string  `dynamodbav:"Time"`
+	Temp          float64 `dynamodbav:"Temp"`
+	RainProb      float64 `dynamodbav:"RainProb"`
+	Precipitation float64 `dynamodbav:"Precipitation"`
+	WindSpeed     float64 `dynamodbav:"WindSpeed"`
+	CloudCover    float64 `dynamodbav:"CloudCover"`
 }
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// ambiguousPlaceError is returned when a place name matches several places.
+type ambiguousPlaceError struct {
+	Message    string  `json:"message"`
+	Candidates []Place `json:"candidates"`
+}
+
+func (e *ambiguousPlaceError) Error() string {
+	return e.Message
+}
+
+// resolvePlace replaces a q=<place name> (and optional country=) query by the
+// coordinates of the matching place. Requests that already carry lat/lon are
+// returned unchanged.
+func (wsvc *WeatherService) resolvePlace(req Request) (Request, error) {
+	name := req.QueryParameters["q"]
+	if name == "" || req.QueryParameters["lat"] != "" || req.QueryParameters["lon"] != "" {
+		return req, nil
+	}
+
+	if wsvc.Geocoder == nil {
+		return req, invalidParam("q", fmt.Errorf("Place name lookup is not available, use lat/lon"))
+	}
+
+	country := req.QueryParameters["country"]
+	places := wsvc.Geocoder.Lookup(name, country)
+
+	logrus.WithFields(logrus.Fields{
+		"q":       name,
+		"country": country,
+		"matches": len(places),
+	}).Info("Resolved place name")
+
+	switch len(places) {
+	case 0:
+		return req, &serviceError{StatusCode: http.StatusNotFound, Code: CodePlaceNotFound, Message: fmt.Sprintf("Place not found: %s", name)}
+	case 1:
+	default:
+		return req, &ambiguousPlaceError{
+			Message:    "Ambiguous place name, narrow it down with country or use lat/lon",
+			Candidates: places,
+		}
+	}
+
+	query := make(map[string]string, len(req.QueryParameters)+2)
+	for k, v := range req.QueryParameters {
+		query[k] = v
+	}
+	query["lat"] = places[0].Latitude
+	query["lon"] = places[0].Longitude
+	req.QueryParameters = query
+
+	return req, nil
+}
@@ -0,0 +1,103 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+	"weather-service/helper/mockutil"
+	"weather-service/internal/handler"
+	"weather-service/internal/handler/mocks"
+)
+
+var _ = Describe("Place", mockutil.Mockable(func(helper *mockutil.Helper) {
+	var (
+		mockCache          *mocks.MockCache
+		mockForecastClient *mocks.MockForecastClient
+		mockGeocoder       *mocks.MockGeocoder
+		ws                 *handler.WeatherService
+	)
+
+	today := time.Now().UTC().Format("2006-01-02")
+
+	BeforeEach(func() {
+		mockCache = mocks.NewMockCache(helper.Controller())
+		mockForecastClient = mocks.NewMockForecastClient(helper.Controller())
+		mockGeocoder = mocks.NewMockGeocoder(helper.Controller())
+		ws = handler.NewWeatherService(mockForecastClient, mockCache)
+		ws.Geocoder = mockGeocoder
+	})
+
+	When("place name matches one place", func() {
+		BeforeEach(func() {
+			mockGeocoder.EXPECT().Lookup("Sofia", "BG").Return([]handler.Place{
+				{Name: "Sofia", Country: "BG", Latitude: "42.69751", Longitude: "23.32415"},
+			}).Times(1)
+			key := fmt.Sprintf("42.70_23.32_%s", today)
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{Key: key, TempMax: 27}, nil).Times(1)
+		})
+
+		It("should return the weather of its coordinates", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"q": "Sofia", "country": "BG", "date": today},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+
+			var wsr handler.WeatherServiceResponse
+			Expect(json.Unmarshal([]byte(res.Body), &wsr)).To(Succeed())
+			Expect(wsr.Latitude).To(Equal("42.70"))
+			Expect(wsr.Temperature).To(Equal(27.0))
+		})
+	})
+
+	When("place name is ambiguous", func() {
+		BeforeEach(func() {
+			mockGeocoder.EXPECT().Lookup("London", "").Return([]handler.Place{
+				{Name: "London", Country: "GB", Latitude: "51.50853", Longitude: "-0.12574"},
+				{Name: "London", Country: "CA", Latitude: "42.98339", Longitude: "-81.23304"},
+			}).Times(1)
+			mockCache.EXPECT().Get(gomock.Any()).Times(0)
+		})
+
+		It("should return the candidates", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"q": "London"},
+			})
+			Expect(res.StatusCode).To(Equal(300))
+			Expect(res.Body).To(ContainSubstring("\"candidates\""))
+			Expect(res.Body).To(ContainSubstring("\"country\":\"CA\""))
+		})
+	})
+
+	When("place name is unknown", func() {
+		BeforeEach(func() {
+			mockGeocoder.EXPECT().Lookup("Atlantis", "").Return(nil).Times(1)
+		})
+
+		It("should return error response", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"q": "Atlantis"},
+			})
+			Expect(res.StatusCode).To(Equal(404))
+			Expect(res.Body).To(ContainSubstring("Place not found"))
+		})
+	})
+
+	When("lat/lon are provided too", func() {
+		BeforeEach(func() {
+			mockGeocoder.EXPECT().Lookup(gomock.Any(), gomock.Any()).Times(0)
+			key := fmt.Sprintf("42.00_23.00_%s", today)
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{Key: key}, nil).Times(1)
+		})
+
+		It("should use the coordinates", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"q": "Sofia", "lat": "42.0", "lon": "23.0", "date": today},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+		})
+	})
+}))
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"weather-service/internal/summary"
+	"weather-service/internal/uv"
+	"weather-service/internal/wmo"
+)
+
+// presentation holds the query parameters that shape daily responses.
+type presentation struct {
+	units  Units
+	fields []string
+	// skinType limits safe exposure times to one skin type, 0 for all.
+	skinType uv.SkinType
+	// lang is the language of conditions and summaries, catalog holds the
+	// messages of the summary.
+	lang    string
+	catalog summary.Catalog
+}
+
+// parsePresentation reads units, fields, skinType and lang.
+func parsePresentation(query map[string]string) (presentation, error) {
+	units, err := parseUnits(query)
+	if err != nil {
+		return presentation{}, err
+	}
+
+	fields, err := parseFields(query)
+	if err != nil {
+		return presentation{}, err
+	}
+
+	var skinType uv.SkinType
+	if value := query["skinType"]; value != "" {
+		if skinType, err = uv.ParseSkinType(value); err != nil {
+			return presentation{}, invalidParam("skinType", err)
+		}
+	}
+
+	lang := query["lang"]
+	if lang == "" {
+		lang = summary.DefaultLanguage
+	}
+	catalog, ok := summary.Lookup(lang)
+	if !ok {
+		return presentation{}, invalidParam("lang", fmt.Errorf("Invalid lang: %s, expected one of %s", lang, strings.Join(summary.Languages(), ", ")))
+	}
+
+	return presentation{units: units, fields: fields, skinType: skinType, lang: lang, catalog: catalog}, nil
+}
+
+// present converts wsr to the requested units, describes its condition,
+// interprets its UV index, summarises it and restricts it to the requested
+// fields.
+func (p presentation) present(wsr WeatherServiceResponse) WeatherServiceResponse {
+	wsr = withCondition(p.units.convert(wsr), p.lang, time.Now())
+	return selectFields(withSummary(withUV(wsr, p.skinType), p.catalog), p.fields)
+}
+
+// withCondition sets the condition, icon and severity of the weather code of
+// wsr in lang. The icon is the night one when now is before sunrise or after
+// sunset of the day of wsr.
+func withCondition(wsr WeatherServiceResponse, lang string, now time.Time) WeatherServiceResponse {
+	wsr.Condition, wsr.Icon, wsr.Severity = "", "", ""
+	if wsr.ConditionCode == nil {
+		return wsr
+	}
+	code, ok := wmo.Lookup(*wsr.ConditionCode)
+	if !ok {
+		return wsr
+	}
+	wsr.Condition = code.Description(lang)
+	wsr.Icon = code.Icon(isNight(wsr, now))
+	wsr.Severity = string(code.Severity)
+	return wsr
+}
+
+// isNight reports whether now is on the day of wsr, but outside its daylight.
+// It is false when the sun times of wsr are unknown.
+func isNight(wsr WeatherServiceResponse, now time.Time) bool {
+	sunrise, err := time.Parse(time.RFC3339, wsr.Sunrise)
+	if err != nil {
+		return false
+	}
+	sunset, err := time.Parse(time.RFC3339, wsr.Sunset)
+	if err != nil {
+		return false
+	}
+	now = now.In(sunrise.Location())
+	return now.Format(dateLayout) == wsr.Date && (now.Before(sunrise) || !now.Before(sunset))
+}
+
+// withSummary sets the summary of wsr, which must be converted and have its
+// UV category set.
+func withSummary(wsr WeatherServiceResponse, catalog summary.Catalog) WeatherServiceResponse {
+	wsr.Summary = catalog.Summarise(summary.Day{
+		WeatherCode:     wsr.ConditionCode,
+		TempMax:         wsr.Temperature,
+		TempMin:         wsr.TemperatureMin,
+		Fahrenheit:      wsr.Units != nil && wsr.Units.Temperature == Fahrenheit,
+		RainProbability: wsr.RainProbability,
+		UVCategory:      uv.Category(wsr.UVCategory),
+	})
+	return wsr
+}
+
+// withUV sets the UV risk category, protection measures and safe exposure
+// times of wsr, for skinType only unless it is 0. They are left out when the
+// UV index is unknown.
+func withUV(wsr WeatherServiceResponse, skinType uv.SkinType) WeatherServiceResponse {
+	wsr.UVCategory, wsr.UVProtection, wsr.SafeExposure = "", nil, nil
+	if wsr.UVIndex == nil {
+		return wsr
+	}
+
+	category := uv.CategoryOf(*wsr.UVIndex)
+	wsr.UVCategory = string(category)
+	wsr.UVProtection = category.Protection()
+
+	skinTypes := uv.SkinTypes
+	if skinType != 0 {
+		skinTypes = []uv.SkinType{skinType}
+	}
+	for _, t := range skinTypes {
+		if d, ok := uv.SafeExposure(*wsr.UVIndex, t); ok {
+			wsr.SafeExposure = append(wsr.SafeExposure, SafeExposure{SkinType: t.String(), Minutes: int(math.Floor(d.Minutes()))})
+		}
+	}
+	return wsr
+}
@@ -0,0 +1,159 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+	"weather-service/helper/mockutil"
+	"weather-service/internal/handler"
+	"weather-service/internal/handler/mocks"
+)
+
+// moderateUVJSON is how the UV fields of a day with a UV index of 3 are encoded.
+const moderateUVJSON = `"uvCategory":"moderate","uvProtection":["Seek shade during midday hours","Wear a shirt, a hat and sunglasses","Apply SPF 30+ sunscreen"],` +
+	`"safeExposure":[{"skinType":"I","minutes":44},{"skinType":"II","minutes":55},{"skinType":"III","minutes":77},{"skinType":"IV","minutes":100},{"skinType":"V","minutes":133},{"skinType":"VI","minutes":222}],`
+
+var _ = Describe("UV guidance", mockutil.Mockable(func(helper *mockutil.Helper) {
+	var (
+		mockCache          *mocks.MockCache
+		mockForecastClient *mocks.MockForecastClient
+		ws                 *handler.WeatherService
+	)
+
+	today := time.Now().UTC().Format("2006-01-02")
+	uvIndex := 9.2
+	rainProbability := 10.0
+	key := fmt.Sprintf("42.00_23.00_%s", today)
+
+	BeforeEach(func() {
+		mockCache = mocks.NewMockCache(helper.Controller())
+		mockForecastClient = mocks.NewMockForecastClient(helper.Controller())
+		ws = handler.NewWeatherService(mockForecastClient, mockCache)
+	})
+
+	When("the UV index is very high", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{Key: key, TempMax: 30, UVIndex: &uvIndex}, nil).Times(1)
+		})
+
+		It("should return the category, protection and safe exposure per skin type", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+
+			var wsr handler.WeatherServiceResponse
+			Expect(json.Unmarshal([]byte(res.Body), &wsr)).To(Succeed())
+			Expect(wsr.UVCategory).To(Equal("very high"))
+			Expect(wsr.UVProtection).To(ContainElement("Seek shade"))
+			Expect(wsr.SafeExposure).To(HaveLen(6))
+			Expect(wsr.SafeExposure[0]).To(Equal(handler.SafeExposure{SkinType: "I", Minutes: 14}))
+		})
+
+		It("should only estimate safe exposure for the requested skin type", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "skinType": "III"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+
+			var wsr handler.WeatherServiceResponse
+			Expect(json.Unmarshal([]byte(res.Body), &wsr)).To(Succeed())
+			Expect(wsr.SafeExposure).To(Equal([]handler.SafeExposure{{SkinType: "III", Minutes: 25}}))
+		})
+
+		It("should add them to a selected UV index", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "fields": "uv_index_max", "skinType": "1"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Body).To(ContainSubstring(`"uvIndex":9.2,"uvCategory":"very high","uvProtection":[`))
+			Expect(res.Body).To(ContainSubstring(`"safeExposure":[{"skinType":"I","minutes":14}],"units"`))
+		})
+	})
+
+	When("there is no UV", func() {
+		BeforeEach(func() {
+			noUV := 0.0
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{Key: key, TempMax: 2, UVIndex: &noUV}, nil).Times(1)
+		})
+
+		It("should not estimate safe exposure", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Body).To(ContainSubstring(`"uvCategory":"low"`))
+			Expect(res.Body).ToNot(ContainSubstring(`safeExposure`))
+		})
+	})
+
+	When("the UV index is unknown", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{Key: key, TempMax: 2}, nil).Times(1)
+		})
+
+		It("should leave out the UV index and its interpretation", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Body).ToNot(ContainSubstring(`"uv`))
+			Expect(res.Body).ToNot(ContainSubstring(`safeExposure`))
+			Expect(res.Body).ToNot(ContainSubstring(`UV`))
+		})
+	})
+
+	When("a summary is requested", func() {
+		BeforeEach(func() {
+			code, low := 1, 14.6
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{Key: key, TempMax: 26.7, TempMin: &low, UVIndex: &uvIndex, RainProb: &rainProbability, ConditionCode: &code}, nil).Times(1)
+		})
+
+		It("should summarise the day", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+
+			var wsr handler.WeatherServiceResponse
+			Expect(json.Unmarshal([]byte(res.Body), &wsr)).To(Succeed())
+			Expect(wsr.Summary).To(Equal("Mostly sunny, high of 27°C, low of 15°C, low chance of rain, very high UV"))
+		})
+
+		It("should summarise it in the requested language and units", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "lang": "bg", "units": "imperial", "fields": "summary"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Body).To(ContainSubstring(`"longitude":"23.00","summary":"Предимно слънчево, максимална 80°F, минимална 58°F, малка вероятност за валежи, много висок UV индекс","units"`))
+		})
+	})
+
+	When("the summary language is unknown", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(gomock.Any()).Times(0)
+		})
+
+		It("should return 400", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "lang": "xx"},
+			})
+			Expect(res.StatusCode).To(Equal(400))
+			Expect(res.Body).To(ContainSubstring("Invalid lang: xx, expected one of bg, en"))
+		})
+	})
+
+	When("the skin type is unknown", func() {
+		It("should return 400", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "skinType": "VII"},
+			})
+			Expect(res.StatusCode).To(Equal(400))
+			Expect(res.Body).To(ContainSubstring(`"name":"skinType"`))
+		})
+	})
+}))
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+	"weather-service/internal/astro"
+)
+
+// providerTimeLayout is how Open-Meteo reports local times with timezone=auto.
+const providerTimeLayout = "2006-01-02T15:04"
+
+// fromProvider prepares the days the provider returned for the given
+// normalised coordinates. The timezone it reports is learned first, so that
+// sun times get the location's offset.
+func (wsvc *WeatherService) fromProvider(fm ForecastMap, lat, lon string) ForecastMap {
+	wsvc.learnTimezone(lat, lon, providerTimezone(fm))
+	return wsvc.withSunTimes(withLocation(fm, lat, lon), lat, lon)
+}
+
+// providerTimezone returns the timezone the provider reported for the days
+// of fm, or "" when it reported none.
+func providerTimezone(fm ForecastMap) string {
+	for _, forecast := range fm {
+		if forecast.Timezone != "" {
+			return forecast.Timezone
+		}
+	}
+	return ""
+}
+
+// withSunTimes returns fm with sunrise, sunset and solar noon as ISO 8601
+// times with the offset of the location's timezone. Values the provider did
+// not report are computed from lat/lon and the date.
+func (wsvc *WeatherService) withSunTimes(fm ForecastMap, lat, lon string) ForecastMap {
+	loc := wsvc.locationTimezone(lat, lon)
+	latitude, _ := strconv.ParseFloat(lat, 64)
+	longitude, _ := strconv.ParseFloat(lon, 64)
+
+	withSun := make(ForecastMap, len(fm))
+	for date, forecast := range fm {
+		day, err := time.ParseInLocation(dateLayout, date, loc)
+		if err != nil {
+			withSun[date] = forecast
+			continue
+		}
+
+		sun := astro.SunTimes(day, latitude, longitude)
+		forecast.Sunrise = sunTime(forecast.Sunrise, sun.Sunrise, loc)
+		forecast.Sunset = sunTime(forecast.Sunset, sun.Sunset, loc)
+		forecast.SolarNoon = sunTime(forecast.SolarNoon, sun.Noon, loc)
+		if forecast.DaylightDuration == nil {
+			daylight := sun.Daylight.Seconds()
+			forecast.DaylightDuration = &daylight
+		}
+		withSun[date] = forecast
+	}
+	return withSun
+}
+
+// sunTime formats the provider's local time in loc, or computed when the
+// provider has none. It is empty when neither is known.
+func sunTime(provided string, computed time.Time, loc *time.Location) string {
+	if provided != "" {
+		if t, err := time.ParseInLocation(providerTimeLayout, provided, loc); err == nil {
+			return t.Format(time.RFC3339)
+		}
+		if t, err := time.Parse(time.RFC3339, provided); err == nil {
+			return t.In(loc).Format(time.RFC3339)
+		}
+	}
+	if computed.IsZero() {
+		return ""
+	}
+	return computed.In(loc).Truncate(time.Minute).Format(time.RFC3339)
+}
@@ -0,0 +1,100 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+	"weather-service/helper/mockutil"
+	"weather-service/internal/astro"
+	"weather-service/internal/handler"
+	"weather-service/internal/handler/mocks"
+)
+
+var _ = Describe("Sun times", mockutil.Mockable(func(helper *mockutil.Helper) {
+	var (
+		mockCache          *mocks.MockCache
+		mockForecastClient *mocks.MockForecastClient
+		mockTimezones      *mocks.MockTimezoneLocator
+		ws                 *handler.WeatherService
+		cached             *handler.CachedWeather
+	)
+
+	sofia, _ := time.LoadLocation("Europe/Sofia")
+	today := time.Now().In(sofia).Format("2006-01-02")
+	key := fmt.Sprintf("42.70_23.32_%s", today)
+
+	BeforeEach(func() {
+		mockCache = mocks.NewMockCache(helper.Controller())
+		mockForecastClient = mocks.NewMockForecastClient(helper.Controller())
+		mockTimezones = mocks.NewMockTimezoneLocator(helper.Controller())
+		ws = handler.NewWeatherService(mockForecastClient, mockCache)
+		ws.Timezones = mockTimezones
+
+		mockTimezones.EXPECT().Timezone("42.70", "23.32").Return("Europe/Sofia").AnyTimes()
+		mockCache.EXPECT().Get(key).Return(nil, nil).Times(1)
+		mockCache.EXPECT().Put(key, gomock.Any()).DoAndReturn(func(_ string, cw *handler.CachedWeather) error {
+			cached = cw
+			return nil
+		}).Times(1)
+	})
+
+	get := func() handler.WeatherServiceResponse {
+		res := ws.Handle(context.TODO(), handler.Request{
+			QueryParameters: map[string]string{"lat": "42.70", "lon": "23.32"},
+		})
+		Expect(res.StatusCode).To(Equal(200))
+
+		var wsr handler.WeatherServiceResponse
+		Expect(json.Unmarshal([]byte(res.Body), &wsr)).To(Succeed())
+		return wsr
+	}
+
+	When("the provider reports them", func() {
+		daylight := 40000.0
+		BeforeEach(func() {
+			mockForecastClient.EXPECT().GetForecast("42.70", "23.32").Return(handler.ForecastMap{
+				today: handler.Forecast{Temp2max: 20, Sunrise: today + "T07:30", Sunset: today + "T18:40", DaylightDuration: &daylight},
+			}, nil).Times(1)
+		})
+
+		It("should return them with the location's offset and cache them", func() {
+			sunrise, _ := time.ParseInLocation("2006-01-02T15:04", today+"T07:30", sofia)
+			sunset, _ := time.ParseInLocation("2006-01-02T15:04", today+"T18:40", sofia)
+
+			wsr := get()
+			Expect(wsr.Sunrise).To(Equal(sunrise.Format(time.RFC3339)))
+			Expect(wsr.Sunset).To(Equal(sunset.Format(time.RFC3339)))
+			Expect(wsr.Sunrise).To(MatchRegexp(`\+0[23]:00$`))
+			Expect(wsr.SolarNoon).To(HavePrefix(today + "T13:"))
+			Expect(*wsr.DaylightDuration).To(Equal(40000.0))
+
+			Expect(cached.Sunrise).To(Equal(wsr.Sunrise))
+			Expect(cached.Sunset).To(Equal(wsr.Sunset))
+			Expect(cached.SolarNoon).To(Equal(wsr.SolarNoon))
+			Expect(*cached.DaylightDuration).To(Equal(40000.0))
+		})
+	})
+
+	When("the provider omits them", func() {
+		BeforeEach(func() {
+			mockForecastClient.EXPECT().GetForecast("42.70", "23.32").Return(handler.ForecastMap{
+				today: handler.Forecast{Temp2max: 20},
+			}, nil).Times(1)
+		})
+
+		It("should compute them from the location and date", func() {
+			day, _ := time.ParseInLocation("2006-01-02", today, sofia)
+			sun := astro.SunTimes(day, 42.70, 23.32)
+
+			wsr := get()
+			Expect(wsr.Sunrise).To(Equal(sun.Sunrise.In(sofia).Truncate(time.Minute).Format(time.RFC3339)))
+			Expect(wsr.Sunset).To(Equal(sun.Sunset.In(sofia).Truncate(time.Minute).Format(time.RFC3339)))
+			Expect(*wsr.DaylightDuration).To(Equal(sun.Daylight.Seconds()))
+			Expect(cached.Sunrise).To(Equal(wsr.Sunrise))
+		})
+	})
+}))
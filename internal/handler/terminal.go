@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"weather-service/internal/summary"
+	"weather-service/internal/uv"
+)
+
+// terminalDays is the number of days drawn for terminal requests without a
+// date or range.
+const terminalDays = 7
+
+// terminalAgents are the User-Agent prefixes of command line clients, which
+// get the ansi format unless they ask for another one.
+var terminalAgents = []string{"curl/", "wget/"}
+
+// terminalFormat returns the terminal format req is answered in, ansi or
+// plain, or "" when it asks for another one. ?nocolor turns ansi into plain.
+func terminalFormat(req Request) string {
+	format := strings.ToLower(req.QueryParameters["format"])
+	if format == "" && isTerminalAgent(req.Headers["user-agent"]) {
+		if accept := req.Headers["accept"]; accept == "" || accept == "*/*" {
+			format = "ansi"
+		}
+	}
+	if format != "ansi" && format != "plain" {
+		return ""
+	}
+	if _, ok := req.QueryParameters["nocolor"]; ok {
+		return "plain"
+	}
+	return format
+}
+
+func isTerminalAgent(userAgent string) bool {
+	userAgent = strings.ToLower(userAgent)
+	for _, prefix := range terminalAgents {
+		if strings.HasPrefix(userAgent, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// terminalRequest turns a terminal request into a range request, so that a
+// single date is drawn as a one-row table. Requests without a date or range
+// get the next terminalDays days, or the forecast window when it is shorter.
+func terminalRequest(req Request, format string, forecastDays int) Request {
+	query := make(map[string]string, len(req.QueryParameters)+2)
+	for k, v := range req.QueryParameters {
+		query[k] = v
+	}
+	query["format"] = format
+	switch {
+	case isRangeRequest(req):
+	case query["date"] != "":
+		query["start"] = query["date"]
+	default:
+		days := terminalDays
+		if forecastDays < days {
+			days = forecastDays
+		}
+		query["days"] = strconv.Itoa(days)
+	}
+	req.QueryParameters = query
+	return req
+}
+
+// skyGlyphs are the ASCII glyphs of the sky of a day, all equally wide.
+var skyGlyphs = map[summary.Sky]string{
+	summary.Sunny:        " -O- ",
+	summary.MostlySunny:  " -O~ ",
+	summary.PartlyCloudy: " O~~ ",
+	summary.Cloudy:       " ~~~ ",
+	summary.Fog:          " = = ",
+	summary.Drizzle:      " ' ' ",
+	summary.Rain:         " /// ",
+	summary.Snow:         " * * ",
+	summary.Thunderstorm: " ~/~ ",
+}
+
+const unknownSkyGlyph = "  ?  "
+
+// uvColors are the ANSI colours of the WHO UV risk categories.
+var uvColors = map[uv.Category]string{
+	uv.Low:      "32",
+	uv.Moderate: "33",
+	uv.High:     "38;5;208",
+	uv.VeryHigh: "31",
+	uv.Extreme:  "35",
+}
+
+var windSpeedLabels = map[string]string{
+	KilometresPerHour: "km/h",
+	MetresPerSecond:   "m/s",
+	MilesPerHour:      "mph",
+	Knots:             "kn",
+	Beaufort:          "Bft",
+}
+
+// terminalCell is a table cell. color is an ANSI SGR parameter, numbers are
+// aligned right.
+type terminalCell struct {
+	text   string
+	color  string
+	number bool
+}
+
+var terminalHeader = []terminalCell{
+	{text: "Date"}, {text: ""}, {text: "Condition"}, {text: "High", number: true}, {text: "Low", number: true},
+	{text: "Rain", number: true}, {text: "UV"}, {text: "Wind"},
+}
+
+func encodeANSI(days []WeatherServiceResponse, _ bool) ([]byte, error) {
+	return renderTerminal(days, true)
+}
+
+func encodePlain(days []WeatherServiceResponse, _ bool) ([]byte, error) {
+	return renderTerminal(days, false)
+}
+
+// renderTerminal draws days as an aligned table, colouring temperatures and
+// UV when color is set.
+func renderTerminal(days []WeatherServiceResponse, color bool) ([]byte, error) {
+	rows := [][]terminalCell{terminalHeader}
+	for _, day := range days {
+		row, err := terminalRow(day)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+
+	widths := make([]int, len(terminalHeader))
+	for _, row := range rows {
+		for i, cell := range row {
+			if n := utf8.RuneCountInString(cell.text); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if len(days) > 0 {
+		fmt.Fprintf(&buf, "Weather for %s, %s (%s)\n\n", days[0].Latitude, days[0].Longitude, days[0].Timezone)
+	}
+	for r, row := range rows {
+		var line strings.Builder
+		for i, cell := range row {
+			if i > 0 {
+				line.WriteString("  ")
+			}
+			padding := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell.text))
+			if i == len(row)-1 && !cell.number {
+				padding = ""
+			}
+			text := cell.text + padding
+			if cell.number {
+				text = padding + cell.text
+			}
+			if color && cell.color != "" {
+				text = "\x1b[" + cell.color + "m" + text + "\x1b[0m"
+			}
+			line.WriteString(text)
+		}
+		if color && r == 0 {
+			buf.WriteString("\x1b[1m" + line.String() + "\x1b[0m\n")
+			continue
+		}
+		buf.WriteString(line.String())
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// terminalRow returns the cells of a day, which must have its units set.
+func terminalRow(day WeatherServiceResponse) ([]terminalCell, error) {
+	date, err := time.Parse(dateLayout, day.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	glyph := unknownSkyGlyph
+	if day.ConditionCode != nil {
+		if sky, ok := summary.SkyOf(*day.ConditionCode); ok {
+			glyph = skyGlyphs[sky]
+		}
+	}
+
+	fahrenheit := day.Units != nil && day.Units.Temperature == Fahrenheit
+	low := terminalCell{text: "-", number: true}
+	if day.TemperatureMin != nil {
+		low = temperatureCell(*day.TemperatureMin, fahrenheit)
+	}
+
+	category := uv.Category(day.UVCategory)
+	uvText := "-"
+	if day.UVIndex != nil {
+		uvText = strconv.FormatFloat(math.Round(*day.UVIndex), 'f', 0, 64) + " " + string(category)
+	}
+
+	rain := "-"
+	if day.RainProbability != nil {
+		rain = strconv.FormatFloat(*day.RainProbability, 'f', 0, 64) + "%"
+	}
+
+	wind := "-"
+	if day.WindSpeedMax != nil {
+		unit := KilometresPerHour
+		if day.Units != nil {
+			unit = day.Units.WindSpeed
+		}
+		wind = strconv.FormatFloat(math.Round(*day.WindSpeedMax), 'f', 0, 64) + " " + windSpeedLabels[unit]
+		if day.WindDirectionCompass != "" {
+			wind += " " + day.WindDirectionCompass
+		}
+	}
+
+	return []terminalCell{
+		{text: date.Format("Mon 02 Jan")},
+		{text: glyph},
+		{text: day.Condition},
+		temperatureCell(day.Temperature, fahrenheit),
+		low,
+		{text: rain, number: true},
+		{text: uvText, color: uvColors[category]},
+		{text: wind},
+	}, nil
+}
+
+// temperatureCell formats a temperature, coloured from blue for frost to
+// red for heat.
+func temperatureCell(value float64, fahrenheit bool) terminalCell {
+	symbol, celsius := "°C", value
+	if fahrenheit {
+		symbol, celsius = "°F", (value-32)*5/9
+	}
+
+	var color string
+	switch {
+	case celsius <= 0:
+		color = "94"
+	case celsius < 10:
+		color = "36"
+	case celsius < 20:
+		color = "32"
+	case celsius < 30:
+		color = "33"
+	default:
+		color = "31"
+	}
+	return terminalCell{text: fmt.Sprintf("%d%s", int(math.Round(value)), symbol), color: color, number: true}
+}
@@ -0,0 +1,126 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"strings"
+	"time"
+	"weather-service/helper/mockutil"
+	"weather-service/internal/handler"
+	"weather-service/internal/handler/mocks"
+)
+
+var _ = Describe("Terminal", mockutil.Mockable(func(helper *mockutil.Helper) {
+	var (
+		mockCache          *mocks.MockCache
+		mockForecastClient *mocks.MockForecastClient
+		ws                 *handler.WeatherService
+	)
+
+	now := time.Now().UTC()
+	uvIndex := 3.0
+	rainProbability := 10.0
+	today := now.Format("2006-01-02")
+	curl := map[string]string{"user-agent": "curl/8.5.0", "accept": "*/*"}
+
+	cached := func(key string) (*handler.CachedWeather, error) {
+		code, low, speed, direction := 61, 12.0, 15.2, 290.0
+		return &handler.CachedWeather{
+			Key:           key,
+			TempMax:       23.5,
+			TempMin:       &low,
+			UVIndex:       &uvIndex,
+			RainProb:      &rainProbability,
+			ConditionCode: &code,
+			WindSpeedMax:  &speed,
+			WindDirection: &direction,
+		}, nil
+	}
+
+	BeforeEach(func() {
+		mockCache = mocks.NewMockCache(helper.Controller())
+		mockForecastClient = mocks.NewMockForecastClient(helper.Controller())
+		ws = handler.NewWeatherService(mockForecastClient, mockCache)
+	})
+
+	When("curl asks for a place without a date", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(gomock.Any()).DoAndReturn(cached).Times(7)
+		})
+
+		It("should draw the 7-day forecast in colour", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0"},
+				Headers:         curl,
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Headers).To(HaveKeyWithValue("Content-Type", "text/plain; charset=utf-8"))
+			lines := strings.Split(strings.TrimSpace(res.Body), "\n")
+			Expect(lines).To(HaveLen(10))
+			Expect(lines[0]).To(Equal("Weather for 42.00, 23.00 (UTC)"))
+			Expect(lines[2]).To(HavePrefix("\x1b[1mDate"))
+			Expect(lines[3]).To(HavePrefix(now.Format("Mon 02 Jan")))
+			Expect(lines[9]).To(HavePrefix(now.AddDate(0, 0, 6).Format("Mon 02 Jan")))
+			Expect(res.Body).To(ContainSubstring("\x1b[33m24°C\x1b[0m"))
+			Expect(res.Body).To(ContainSubstring("\x1b[33m3 moderate\x1b[0m"))
+		})
+
+		It("should leave colours out with nocolor", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "nocolor": ""},
+				Headers:         curl,
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Body).ToNot(ContainSubstring("\x1b["))
+		})
+	})
+
+	When("plain text is requested for a date", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(fmt.Sprintf("42.00_23.00_%s", today)).DoAndReturn(cached).Times(1)
+		})
+
+		It("should draw an aligned table", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": today, "format": "plain", "wind_speed_unit": "ms"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Body).To(Equal("Weather for 42.00, 23.00 (UTC)\n\n" +
+				"Date               Condition    High   Low  Rain  UV          Wind\n" +
+				now.Format("Mon 02 Jan") + "   ///   Slight rain  24°C  12°C   10%  3 moderate  4 m/s WNW\n"))
+		})
+	})
+
+	When("a terminal client asks for json", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(fmt.Sprintf("42.00_23.00_%s", today)).DoAndReturn(cached).Times(1)
+		})
+
+		It("should return json", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": today},
+				Headers:         map[string]string{"user-agent": "Wget/1.21.4", "accept": "application/json"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Headers).To(HaveKeyWithValue("Content-Type", "application/json"))
+		})
+	})
+
+	When("plain text is accepted", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(fmt.Sprintf("42.00_23.00_%s", today)).DoAndReturn(cached).Times(1)
+		})
+
+		It("should return the summary rather than the table", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": today},
+				Headers:         map[string]string{"accept": "text/plain"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Body).To(Equal("Rain, high of 24°C, low of 12°C, low chance of rain, moderate UV\n"))
+		})
+	})
+}))
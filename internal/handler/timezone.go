@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"sync"
+	"time"
+	// bundle the IANA database, the Lambda runtime does not ship one
+	_ "time/tzdata"
+)
+
+//go:generate mockgen --source=timezone.go --destination mocks/timezone.go --package mocks
+
+// maxLearnedTimezones bounds how many locations learnedTimezones remembers.
+const maxLearnedTimezones = 10000
+
+// TimezoneLocator resolves the IANA timezone of a location, e.g. from an
+// offline timezone boundary or nearest-place lookup. An empty name means the
+// location is unknown.
+type TimezoneLocator interface {
+	Timezone(lat, lon string) string
+}
+
+// learnedTimezones remembers the timezones the provider reported, by location.
+// It is emptied when it grows past maxLearnedTimezones.
+type learnedTimezones struct {
+	mu    sync.RWMutex
+	zones map[string]string
+}
+
+// locationTimezone returns the timezone dates are interpreted in for the
+// given normalised coordinates: the one the provider reported for them, else
+// the one the TimezoneLocator knows, else UTC.
+func (wsvc *WeatherService) locationTimezone(lat, lon string) *time.Location {
+	wsvc.learned.mu.RLock()
+	name, ok := wsvc.learned.zones[lat+"_"+lon]
+	wsvc.learned.mu.RUnlock()
+	if ok {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+
+	if wsvc.Timezones == nil {
+		return time.UTC
+	}
+
+	name = wsvc.Timezones.Timezone(lat, lon)
+	if name == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// learnTimezone remembers name, as reported by the provider, as the timezone
+// of the given normalised coordinates. Empty and unknown names are ignored.
+func (wsvc *WeatherService) learnTimezone(lat, lon, name string) {
+	if name == "" {
+		return
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return
+	}
+
+	wsvc.learned.mu.Lock()
+	defer wsvc.learned.mu.Unlock()
+	if wsvc.learned.zones == nil || len(wsvc.learned.zones) >= maxLearnedTimezones {
+		wsvc.learned.zones = make(map[string]string)
+	}
+	wsvc.learned.zones[lat+"_"+lon] = name
+}
+
+// todayDate returns the current date in loc.
+func todayDate(loc *time.Location) string {
+	return time.Now().In(loc).Format(dateLayout)
+}
@@ -0,0 +1,178 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+	"weather-service/helper/mockutil"
+	"weather-service/internal/handler"
+	"weather-service/internal/handler/mocks"
+)
+
+var _ = Describe("Timezone", mockutil.Mockable(func(helper *mockutil.Helper) {
+	var (
+		mockCache          *mocks.MockCache
+		mockForecastClient *mocks.MockForecastClient
+		mockTimezones      *mocks.MockTimezoneLocator
+		ws                 *handler.WeatherService
+	)
+
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	losAngeles, _ := time.LoadLocation("America/Los_Angeles")
+
+	BeforeEach(func() {
+		mockCache = mocks.NewMockCache(helper.Controller())
+		mockForecastClient = mocks.NewMockForecastClient(helper.Controller())
+		mockTimezones = mocks.NewMockTimezoneLocator(helper.Controller())
+		ws = handler.NewWeatherService(mockForecastClient, mockCache)
+		ws.Timezones = mockTimezones
+	})
+
+	When("date is missing", func() {
+		today := time.Now().In(tokyo).Format("2006-01-02")
+
+		BeforeEach(func() {
+			mockTimezones.EXPECT().Timezone("35.69", "139.69").Return("Asia/Tokyo").Times(1)
+			key := fmt.Sprintf("35.69_139.69_%s", today)
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{Key: key, TempMax: 23}, nil).Times(1)
+		})
+
+		It("should default to today in the location's timezone", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "35.6895", "lon": "139.69171"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+
+			var wsr handler.WeatherServiceResponse
+			Expect(json.Unmarshal([]byte(res.Body), &wsr)).To(Succeed())
+			Expect(wsr.Date).To(Equal(today))
+			Expect(wsr.Timezone).To(Equal("Asia/Tokyo"))
+		})
+	})
+
+	When("date is yesterday in the location's timezone", func() {
+		yesterday := time.Now().In(losAngeles).AddDate(0, 0, -1).Format("2006-01-02")
+
+		BeforeEach(func() {
+			mockTimezones.EXPECT().Timezone("34.05", "-118.24").Return("America/Los_Angeles").Times(1)
+			mockCache.EXPECT().Get(gomock.Any()).Times(0)
+		})
+
+		It("should return error response", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "34.05", "lon": "-118.24", "date": yesterday},
+			})
+			Expect(res.StatusCode).To(Equal(400))
+			Expect(res.Body).To(ContainSubstring("Date could not be older than today"))
+		})
+	})
+
+	When("a range is requested", func() {
+		today := time.Now().In(tokyo).Format("2006-01-02")
+
+		BeforeEach(func() {
+			mockTimezones.EXPECT().Timezone("35.69", "139.69").Return("Asia/Tokyo").Times(1)
+			key := fmt.Sprintf("35.69_139.69_%s", today)
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{Key: key, TempMax: 23}, nil).Times(1)
+		})
+
+		It("should start the range at today in the location's timezone", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "35.69", "lon": "139.69", "days": "1"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+
+			var days []handler.WeatherServiceResponse
+			Expect(json.Unmarshal([]byte(res.Body), &days)).To(Succeed())
+			Expect(days).To(HaveLen(1))
+			Expect(days[0].Date).To(Equal(today))
+			Expect(days[0].Timezone).To(Equal("Asia/Tokyo"))
+		})
+	})
+
+	When("the provider reports a timezone the locator does not know", func() {
+		honolulu, _ := time.LoadLocation("Pacific/Honolulu")
+		today := time.Now().In(honolulu).Format("2006-01-02")
+
+		BeforeEach(func() {
+			mockTimezones.EXPECT().Timezone("21.31", "-157.86").Return("").Times(1)
+			mockCache.EXPECT().Get(gomock.Any()).Return(nil, nil).Times(1)
+			mockForecastClient.EXPECT().GetForecast("21.31", "-157.86").Return(handler.ForecastMap{
+				today: handler.Forecast{Timezone: "Pacific/Honolulu", Temp2max: 29},
+				time.Now().In(honolulu).AddDate(0, 0, 1).Format("2006-01-02"): handler.Forecast{Timezone: "Pacific/Honolulu", Temp2max: 30},
+			}, nil).Times(1)
+			mockCache.EXPECT().Put(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+			mockCache.EXPECT().Get(fmt.Sprintf("21.31_-157.86_%s", today)).Return(&handler.CachedWeather{Key: fmt.Sprintf("21.31_-157.86_%s", today), TempMax: 29}, nil).Times(1)
+		})
+
+		It("should default to today in it and remember it for the location", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "21.31", "lon": "-157.86"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+
+			var wsr handler.WeatherServiceResponse
+			Expect(json.Unmarshal([]byte(res.Body), &wsr)).To(Succeed())
+			Expect(wsr.Date).To(Equal(today))
+			Expect(wsr.Timezone).To(Equal("Pacific/Honolulu"))
+
+			res = ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "21.31", "lon": "-157.86"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+			Expect(res.Body).To(ContainSubstring(`"timezone":"Pacific/Honolulu"`))
+		})
+	})
+
+	When("a cached item holds another timezone than the default date was resolved in", func() {
+		kiritimati, _ := time.LoadLocation("Pacific/Kiritimati")
+		utcToday := time.Now().UTC().Format("2006-01-02")
+		today := time.Now().In(kiritimati).Format("2006-01-02")
+
+		BeforeEach(func() {
+			mockTimezones.EXPECT().Timezone("1.87", "-157.43").Return("").Times(1)
+			mockCache.EXPECT().Get(fmt.Sprintf("1.87_-157.43_%s", utcToday)).Return(&handler.CachedWeather{Key: fmt.Sprintf("1.87_-157.43_%s", utcToday), TempMax: 30, Timezone: "Pacific/Kiritimati"}, nil).Times(1)
+			if today != utcToday {
+				mockCache.EXPECT().Get(fmt.Sprintf("1.87_-157.43_%s", today)).Return(&handler.CachedWeather{Key: fmt.Sprintf("1.87_-157.43_%s", today), TempMax: 31, Timezone: "Pacific/Kiritimati"}, nil).Times(1)
+			}
+		})
+
+		It("should serve today in the item's timezone", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "1.87", "lon": "-157.43"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+
+			var wsr handler.WeatherServiceResponse
+			Expect(json.Unmarshal([]byte(res.Body), &wsr)).To(Succeed())
+			Expect(wsr.Date).To(Equal(today))
+			Expect(wsr.Timezone).To(Equal("Pacific/Kiritimati"))
+		})
+	})
+
+	When("the locator does not know the location", func() {
+		today := time.Now().UTC().Format("2006-01-02")
+
+		BeforeEach(func() {
+			mockTimezones.EXPECT().Timezone("0.00", "-160.00").Return("").Times(1)
+			key := fmt.Sprintf("0.00_-160.00_%s", today)
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{Key: key, TempMax: 28}, nil).Times(1)
+		})
+
+		It("should fall back to UTC", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				QueryParameters: map[string]string{"lat": "0", "lon": "-160"},
+			})
+			Expect(res.StatusCode).To(Equal(200))
+
+			var wsr handler.WeatherServiceResponse
+			Expect(json.Unmarshal([]byte(res.Body), &wsr)).To(Succeed())
+			Expect(wsr.Timezone).To(Equal("UTC"))
+		})
+	})
+
+}))
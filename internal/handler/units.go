@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"weather-service/internal/wind"
+)
+
+const (
+	Celsius    = "celsius"
+	Fahrenheit = "fahrenheit"
+	Millimetre = "mm"
+	Inch       = "inch"
+	// Wind speed units use Open-Meteo's wind_speed_unit names, Beaufort is
+	// computed locally.
+	KilometresPerHour = "kmh"
+	MetresPerSecond   = "ms"
+	MilesPerHour      = "mph"
+	Knots             = "kn"
+	Beaufort          = "bft"
+)
+
+// Units describes the units of a response. Cached values are always metric
+// and converted only when the response is built.
+type Units struct {
+	Temperature   string `json:"temperature" xml:"temperature"`
+	Precipitation string `json:"precipitation" xml:"precipitation"`
+	WindSpeed     string `json:"windSpeed" xml:"windSpeed"`
+}
+
+var unitSystems = map[string]Units{
+	"metric":   {Temperature: Celsius, Precipitation: Millimetre, WindSpeed: KilometresPerHour},
+	"imperial": {Temperature: Fahrenheit, Precipitation: Inch, WindSpeed: MilesPerHour},
+}
+
+// parseUnits reads units=metric|imperial and the per-field
+// temperature_unit/precipitation_unit/wind_speed_unit overrides.
+func parseUnits(query map[string]string) (Units, error) {
+	system := query["units"]
+	if system == "" {
+		system = "metric"
+	}
+
+	units, ok := unitSystems[system]
+	if !ok {
+		return Units{}, invalidParam("units", fmt.Errorf("Invalid units: %s, expected metric or imperial", system))
+	}
+
+	switch unit := query["temperature_unit"]; unit {
+	case "":
+	case Celsius, Fahrenheit:
+		units.Temperature = unit
+	default:
+		return Units{}, invalidParam("temperature_unit", fmt.Errorf("Invalid temperature_unit: %s, expected celsius or fahrenheit", unit))
+	}
+
+	switch unit := query["precipitation_unit"]; unit {
+	case "":
+	case Millimetre, Inch:
+		units.Precipitation = unit
+	default:
+		return Units{}, invalidParam("precipitation_unit", fmt.Errorf("Invalid precipitation_unit: %s, expected mm or inch", unit))
+	}
+
+	switch unit := query["wind_speed_unit"]; unit {
+	case "":
+	case KilometresPerHour, MetresPerSecond, MilesPerHour, Knots, Beaufort:
+		units.WindSpeed = unit
+	default:
+		return Units{}, invalidParam("wind_speed_unit", fmt.Errorf("Invalid wind_speed_unit: %s, expected kmh, ms, mph, kn or bft", unit))
+	}
+
+	return units, nil
+}
+
+func (u Units) temperature(celsius float64) float64 {
+	if u.Temperature == Fahrenheit {
+		return round(celsius*9/5+32, 1)
+	}
+	return celsius
+}
+
+func (u Units) precipitation(mm float64) float64 {
+	if u.Precipitation == Inch {
+		return round(mm/25.4, 2)
+	}
+	return mm
+}
+
+func (u Units) snowfall(cm float64) float64 {
+	if u.Precipitation == Inch {
+		return round(cm/2.54, 2)
+	}
+	return cm
+}
+
+func (u Units) windSpeed(kmh float64) float64 {
+	switch u.WindSpeed {
+	case MetresPerSecond:
+		return round(kmh/3.6, 1)
+	case MilesPerHour:
+		return round(kmh/1.609344, 1)
+	case Knots:
+		return round(kmh/1.852, 1)
+	case Beaufort:
+		return float64(wind.Beaufort(kmh))
+	}
+	return kmh
+}
+
+// convert returns wsr expressed in u.
+func (u Units) convert(wsr WeatherServiceResponse) WeatherServiceResponse {
+	wsr.Temperature = u.temperature(wsr.Temperature)
+	wsr.TemperatureMin = convertOptional(wsr.TemperatureMin, u.temperature)
+	wsr.ApparentTemperatureMax = convertOptional(wsr.ApparentTemperatureMax, u.temperature)
+	wsr.ApparentTemperatureMin = convertOptional(wsr.ApparentTemperatureMin, u.temperature)
+	wsr.PrecipitationSum = convertOptional(wsr.PrecipitationSum, u.precipitation)
+	wsr.RainSum = convertOptional(wsr.RainSum, u.precipitation)
+	wsr.SnowfallSum = convertOptional(wsr.SnowfallSum, u.snowfall)
+	wsr.WindSpeedMax = convertOptional(wsr.WindSpeedMax, u.windSpeed)
+	wsr.WindGustsMax = convertOptional(wsr.WindGustsMax, u.windSpeed)
+	if wsr.Fields != nil {
+		fields := make(map[string]interface{}, len(wsr.Fields))
+		for name, value := range wsr.Fields {
+			fields[name] = u.convertVariable(name, value)
+		}
+		wsr.Fields = fields
+	}
+	wsr.Units = &u
+	return wsr
+}
+
+// convertOptional converts a value that may be missing.
+func convertOptional(value *float64, convert func(float64) float64) *float64 {
+	if value == nil {
+		return nil
+	}
+	converted := convert(*value)
+	return &converted
+}
+
+// convertVariable converts the value of a registered variable according to
+// its quantity.
+func (u Units) convertVariable(name string, value interface{}) interface{} {
+	number, ok := value.(float64)
+	if !ok {
+		return value
+	}
+	v, _ := LookupVariable(name)
+	switch v.quantity {
+	case quantityTemperature:
+		return u.temperature(number)
+	case quantityPrecipitation:
+		return u.precipitation(number)
+	case quantitySnowfall:
+		return u.snowfall(number)
+	case quantityWindSpeed:
+		return u.windSpeed(number)
+	}
+	return number
+}
+
+// convertHourly returns hwsr expressed in u.
+func (u Units) convertHourly(hwsr HourlyWeatherServiceResponse) HourlyWeatherServiceResponse {
+	hours := make([]HourlyWeather, len(hwsr.Hours))
+	for i, hour := range hwsr.Hours {
+		hour.Temperature = u.temperature(hour.Temperature)
+		hour.Precipitation = u.precipitation(hour.Precipitation)
+		hour.WindSpeed = u.windSpeed(hour.WindSpeed)
+		hours[i] = hour
+	}
+	hwsr.Hours = hours
+	hwsr.Units = &u
+	return hwsr
+}
+
+func round(v float64, decimals int) float64 {
+	pow := math.Pow(10, float64(decimals))
+	return math.Round(v*pow) / pow
+}
@@ -0,0 +1,180 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+	"weather-service/helper/mockutil"
+	"weather-service/internal/handler"
+	"weather-service/internal/handler/mocks"
+)
+
+var _ = Describe("Units", mockutil.Mockable(func(helper *mockutil.Helper) {
+	var (
+		mockCache          *mocks.MockCache
+		mockForecastClient *mocks.MockForecastClient
+		ws                 *handler.WeatherService
+	)
+
+	today := time.Now().UTC().Format("2006-01-02")
+	uvIndex := 3.0
+	key := fmt.Sprintf("42.00_23.00_%s", today)
+
+	BeforeEach(func() {
+		mockCache = mocks.NewMockCache(helper.Controller())
+		mockForecastClient = mocks.NewMockForecastClient(helper.Controller())
+		ws = handler.NewWeatherService(mockForecastClient, mockCache)
+	})
+
+	query := func(params map[string]string) map[string]string {
+		q := map[string]string{"lat": "42.0", "lon": "23.0", "date": today}
+		for k, v := range params {
+			q[k] = v
+		}
+		return q
+	}
+
+	Context("Daily", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{Key: key, TempMax: 25, UVIndex: &uvIndex}, nil).Times(1)
+		})
+
+		When("imperial units are requested", func() {
+			It("should convert temperature to fahrenheit", func() {
+				res := ws.Handle(context.TODO(), handler.Request{QueryParameters: query(map[string]string{"units": "imperial"})})
+				Expect(res.StatusCode).To(Equal(200))
+
+				var wsr handler.WeatherServiceResponse
+				Expect(json.Unmarshal([]byte(res.Body), &wsr)).To(Succeed())
+				Expect(wsr.Temperature).To(Equal(77.0))
+				Expect(*wsr.UVIndex).To(Equal(3.0))
+				Expect(*wsr.Units).To(Equal(handler.Units{Temperature: "fahrenheit", Precipitation: "inch", WindSpeed: "mph"}))
+			})
+		})
+
+		When("a per-field override is requested", func() {
+			It("should only convert that field", func() {
+				res := ws.Handle(context.TODO(), handler.Request{QueryParameters: query(map[string]string{"units": "imperial", "temperature_unit": "celsius"})})
+				Expect(res.StatusCode).To(Equal(200))
+
+				var wsr handler.WeatherServiceResponse
+				Expect(json.Unmarshal([]byte(res.Body), &wsr)).To(Succeed())
+				Expect(wsr.Temperature).To(Equal(25.0))
+				Expect(*wsr.Units).To(Equal(handler.Units{Temperature: "celsius", Precipitation: "inch", WindSpeed: "mph"}))
+			})
+		})
+	})
+
+	Context("Daily amounts", func() {
+		BeforeEach(func() {
+			tempMin, apparentMax, precip, snowfall, hours := 10.0, 30.0, 25.4, 5.08, 3.0
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{
+				Key:             key,
+				TempMax:         25,
+				TempMin:         &tempMin,
+				ApparentTempMax: &apparentMax,
+				PrecipSum:       &precip,
+				SnowfallSum:     &snowfall,
+				PrecipHours:     &hours,
+			}, nil).Times(1)
+		})
+
+		It("should convert temperatures and amounts, but not hours", func() {
+			res := ws.Handle(context.TODO(), handler.Request{QueryParameters: query(map[string]string{"units": "imperial"})})
+			Expect(res.StatusCode).To(Equal(200))
+
+			var wsr handler.WeatherServiceResponse
+			Expect(json.Unmarshal([]byte(res.Body), &wsr)).To(Succeed())
+			Expect(*wsr.TemperatureMin).To(Equal(50.0))
+			Expect(*wsr.ApparentTemperatureMax).To(Equal(86.0))
+			Expect(wsr.ApparentTemperatureMin).To(BeNil())
+			Expect(*wsr.PrecipitationSum).To(Equal(1.0))
+			Expect(*wsr.SnowfallSum).To(Equal(2.0))
+			Expect(*wsr.PrecipitationHours).To(Equal(3.0))
+			Expect(wsr.RainSum).To(BeNil())
+		})
+	})
+
+	Context("Daily wind", func() {
+		BeforeEach(func() {
+			speed, gusts, direction := 36.0, 72.0, 290.0
+			mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{
+				Key:           key,
+				TempMax:       25,
+				WindSpeedMax:  &speed,
+				WindGustsMax:  &gusts,
+				WindDirection: &direction,
+			}, nil).Times(1)
+		})
+
+		DescribeTable("should convert wind speeds",
+			func(unit string, speed, gusts float64) {
+				res := ws.Handle(context.TODO(), handler.Request{QueryParameters: query(map[string]string{"wind_speed_unit": unit})})
+				Expect(res.StatusCode).To(Equal(200))
+
+				var wsr handler.WeatherServiceResponse
+				Expect(json.Unmarshal([]byte(res.Body), &wsr)).To(Succeed())
+				Expect(*wsr.WindSpeedMax).To(Equal(speed))
+				Expect(*wsr.WindGustsMax).To(Equal(gusts))
+				Expect(*wsr.WindDirection).To(Equal(290.0))
+				Expect(wsr.WindDirectionCompass).To(Equal("WNW"))
+				Expect(wsr.Units.WindSpeed).To(Equal(unit))
+			},
+			Entry("km/h", "kmh", 36.0, 72.0),
+			Entry("m/s", "ms", 10.0, 20.0),
+			Entry("mph", "mph", 22.4, 44.7),
+			Entry("knots", "kn", 19.4, 38.9),
+			Entry("Beaufort", "bft", 5.0, 8.0),
+		)
+	})
+
+	Context("Hourly", func() {
+		BeforeEach(func() {
+			hourlyKey := key + "_hourly"
+			mockCache.EXPECT().GetHourly(hourlyKey).Return(&handler.CachedHourlyWeather{
+				Key:   hourlyKey,
+				Hours: []handler.CachedHour{{Time: today + "T00:00", Temp: 10, Precipitation: 12.7, WindSpeed: 16.1}},
+			}, nil).Times(1)
+		})
+
+		It("should convert temperature, precipitation and wind speed", func() {
+			res := ws.Handle(context.TODO(), handler.Request{Path: "/weather/hourly", QueryParameters: query(map[string]string{"units": "imperial"})})
+			Expect(res.StatusCode).To(Equal(200))
+
+			var hwsr handler.HourlyWeatherServiceResponse
+			Expect(json.Unmarshal([]byte(res.Body), &hwsr)).To(Succeed())
+			Expect(hwsr.Hours[0].Temperature).To(Equal(50.0))
+			Expect(hwsr.Hours[0].Precipitation).To(Equal(0.5))
+			Expect(hwsr.Hours[0].WindSpeed).To(Equal(10.0))
+		})
+	})
+
+	Context("Wrong query params", func() {
+		When("unit system is unknown", func() {
+			It("should return error response", func() {
+				res := ws.Handle(context.TODO(), handler.Request{QueryParameters: query(map[string]string{"units": "kelvin"})})
+				Expect(res.StatusCode).To(Equal(400))
+				Expect(res.Body).To(ContainSubstring("Invalid units"))
+			})
+		})
+
+		When("temperature unit is unknown", func() {
+			It("should return error response", func() {
+				res := ws.Handle(context.TODO(), handler.Request{QueryParameters: query(map[string]string{"temperature_unit": "kelvin"})})
+				Expect(res.StatusCode).To(Equal(400))
+				Expect(res.Body).To(ContainSubstring("Invalid temperature_unit"))
+			})
+		})
+
+		When("wind speed unit is unknown", func() {
+			It("should return error response", func() {
+				res := ws.Handle(context.TODO(), handler.Request{QueryParameters: query(map[string]string{"wind_speed_unit": "furlongs"})})
+				Expect(res.StatusCode).To(Equal(400))
+				Expect(res.Body).To(ContainSubstring("Invalid wind_speed_unit"))
+			})
+		})
+	})
+}))
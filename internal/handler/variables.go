@@ -0,0 +1,105 @@
+package handler
+
+// quantity tells how a variable is converted between unit systems.
+type quantity int
+
+const (
+	quantityNone quantity = iota
+	quantityTemperature
+	quantityPrecipitation
+	quantitySnowfall
+	quantityWindSpeed
+)
+
+// Variable is a daily Open-Meteo variable the API can return.
+type Variable struct {
+	// Name is the Open-Meteo daily variable name, also accepted by fields=.
+	Name string
+	// Field is the name of the variable in responses.
+	Field string
+	// Text is set for variables with string values, e.g. ISO 8601 times.
+	Text bool
+	// Archive is set when the archive API provides the variable too.
+	Archive bool
+	// Computed is set for variables computed locally rather than fetched.
+	Computed bool
+	quantity quantity
+}
+
+// variables is the registry of daily variables, in response order.
+var variables = []Variable{
+	{Name: "temperature_2m_max", Field: "temperature", Archive: true, quantity: quantityTemperature},
+	{Name: "temperature_2m_min", Field: "temperatureMin", Archive: true, quantity: quantityTemperature},
+	{Name: "apparent_temperature_max", Field: "apparentTemperatureMax", Archive: true, quantity: quantityTemperature},
+	{Name: "apparent_temperature_min", Field: "apparentTemperatureMin", Archive: true, quantity: quantityTemperature},
+	{Name: "uv_index_max", Field: "uvIndex"},
+	{Name: "precipitation_probability_max", Field: "rainProbability"},
+	{Name: "precipitation_sum", Field: "precipitationSum", Archive: true, quantity: quantityPrecipitation},
+	{Name: "rain_sum", Field: "rainSum", Archive: true, quantity: quantityPrecipitation},
+	{Name: "snowfall_sum", Field: "snowfallSum", Archive: true, quantity: quantitySnowfall},
+	{Name: "precipitation_hours", Field: "precipitationHours", Archive: true},
+	{Name: "wind_speed_10m_max", Field: "windSpeedMax", Archive: true, quantity: quantityWindSpeed},
+	{Name: "wind_gusts_10m_max", Field: "windGustsMax", Archive: true, quantity: quantityWindSpeed},
+	{Name: "wind_direction_10m_dominant", Field: "windDirection", Archive: true},
+	{Name: "weather_code", Field: "conditionCode", Archive: true},
+	{Name: "sunrise", Field: "sunrise", Text: true, Archive: true},
+	{Name: "sunset", Field: "sunset", Text: true, Archive: true},
+	{Name: "daylight_duration", Field: "daylightDuration", Archive: true},
+	{Name: "solar_noon", Field: "solarNoon", Text: true, Archive: true, Computed: true},
+	{Name: "sunshine_duration", Field: "sunshineDuration", Archive: true},
+	{Name: "shortwave_radiation_sum", Field: "shortwaveRadiationSum", Archive: true},
+	{Name: "summary", Field: "summary", Text: true, Archive: true, Computed: true},
+}
+
+// DefaultVariables are always fetched from the forecast API and make up the
+// response when no fields are requested.
+var DefaultVariables = []string{
+	"temperature_2m_max", "uv_index_max", "precipitation_probability_max", "weather_code", "sunrise", "sunset", "daylight_duration",
+	"temperature_2m_min", "apparent_temperature_max", "apparent_temperature_min", "precipitation_sum", "rain_sum", "snowfall_sum", "precipitation_hours",
+	"wind_speed_10m_max", "wind_gusts_10m_max", "wind_direction_10m_dominant",
+}
+
+// DefaultArchiveVariables are always fetched from the archive API.
+var DefaultArchiveVariables = []string{
+	"temperature_2m_max", "precipitation_sum", "weather_code", "sunrise", "sunset", "daylight_duration",
+	"temperature_2m_min", "apparent_temperature_max", "apparent_temperature_min", "rain_sum", "snowfall_sum", "precipitation_hours",
+	"wind_speed_10m_max", "wind_gusts_10m_max", "wind_direction_10m_dominant",
+}
+
+// LookupVariable finds a registered variable by its Open-Meteo or response name.
+func LookupVariable(name string) (Variable, bool) {
+	for _, v := range variables {
+		if v.Name == name || v.Field == name {
+			return v, true
+		}
+	}
+	return Variable{}, false
+}
+
+// FetchedVariables returns the names that are fetched from the provider,
+// leaving out computed variables.
+func FetchedVariables(names []string) []string {
+	var fetched []string
+	for _, name := range names {
+		if v, _ := LookupVariable(name); !v.Computed {
+			fetched = append(fetched, name)
+		}
+	}
+	return fetched
+}
+
+// MergeVariables returns base followed by the names of extra that are not in
+// it yet, keeping their order.
+func MergeVariables(base []string, extra ...string) []string {
+	merged := make([]string, 0, len(base)+len(extra))
+	seen := make(map[string]bool, len(base)+len(extra))
+	for _, names := range [][]string{base, extra} {
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				merged = append(merged, name)
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"sync"
+	"weather-service/internal/logging"
+)
+
+const (
+	maxBatchSize     = 100
+	batchConcurrency = 10
+)
+
+// handleBatch serves POST /weather/batch. Items are served from the cache
+// where possible and all remaining locations are fetched with a single
+// GetForecasts call. A failing item only reports its own error.
+func (wsvc *WeatherService) handleBatch(ctx context.Context, req Request) Response {
+	if req.Method != http.MethodPost {
+		return methodNotAllowedResponse("Batch requests must use POST", http.MethodPost)
+	}
+
+	p, err := parsePresentation(req.QueryParameters)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	var items []BatchItem
+	if err := json.Unmarshal([]byte(req.Body), &items); err != nil {
+		return errorResponse(&serviceError{StatusCode: http.StatusBadRequest, Code: CodeInvalidBody, Message: "Invalid batch body: expected a JSON list of {lat, lon, date}"})
+	}
+
+	if len(items) == 0 {
+		return errorResponse(&serviceError{StatusCode: http.StatusBadRequest, Code: CodeInvalidBody, Message: "Invalid batch body: no items provided"})
+	}
+
+	if len(items) > maxBatchSize {
+		return errorResponse(&serviceError{StatusCode: http.StatusBadRequest, Code: CodeInvalidBody, Message: fmt.Sprintf("Invalid batch body: at most %d items are allowed", maxBatchSize)})
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"items": len(items),
+	}).Info("Going to handle batch request")
+
+	results := make([]BatchItemResult, len(items))
+	queries := make([]weatherQuery, len(items))
+	var valid []int
+	for i, item := range items {
+		results[i] = BatchItemResult{
+			Lat:  item.Lat.String(),
+			Lon:  item.Lon.String(),
+			Date: item.Date,
+		}
+
+		query, err := wsvc.validateWeatherQuery(results[i].Lat, results[i].Lon, item.Date)
+		if err != nil {
+			problem := asServiceError(err).Problem()
+			results[i].Error = &problem
+			continue
+		}
+		queries[i] = query
+		valid = append(valid, i)
+	}
+
+	missing, stale := wsvc.getBatchFromCache(results, queries, valid, p.fields)
+	fetch := fetchFields(p.fields, stale...)
+
+	var upcoming, past []int
+	for _, i := range missing {
+		if queries[i].Historical {
+			past = append(past, i)
+		} else {
+			upcoming = append(upcoming, i)
+		}
+	}
+	if len(past) > 0 {
+		wsvc.getBatchFromArchive(results, queries, past, fetch)
+	}
+	if len(upcoming) > 0 {
+		wsvc.getBatchFromForecastClient(results, queries, upcoming, fetch)
+	}
+
+	for i := range results {
+		if results[i].Weather != nil {
+			wsr := p.present(*results[i].Weather)
+			results[i].Weather = &wsr
+		}
+	}
+
+	return respond(results)
+}
+
+// getBatchFromCache looks the given items up in the cache with bounded
+// concurrency and returns the indexes of the ones that were not found or do
+// not hold every requested field, along with the latter.
+func (wsvc *WeatherService) getBatchFromCache(results []BatchItemResult, queries []weatherQuery, indexes []int, fields []string) ([]int, []*CachedWeather) {
+	found := make([]bool, len(results))
+	stale := make([]*CachedWeather, len(results))
+	sem := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+	for _, i := range indexes {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			key := fmt.Sprintf("%s_%s_%s", queries[i].Lat, queries[i].Lon, queries[i].Date)
+			cachedWeather, err := wsvc.WeatherCache.Get(key)
+			if err != nil || cachedWeather == nil || (queries[i].Historical && !archived(cachedWeather)) {
+				return
+			}
+			if !coversFields(cachedWeather, fields, queries[i].Historical) {
+				stale[i] = cachedWeather
+				return
+			}
+			wsvc.learnTimezone(queries[i].Lat, queries[i].Lon, cachedWeather.Timezone)
+			wsr := CachedDataToWeatherServiceResponse(*cachedWeather)
+			wsr.Timezone = cmp.Or(wsr.Timezone, queries[i].Timezone)
+			results[i].Weather = &wsr
+			found[i] = true
+		}(i)
+	}
+	wg.Wait()
+
+	var missing []int
+	var held []*CachedWeather
+	for _, i := range indexes {
+		if !found[i] {
+			missing = append(missing, i)
+		}
+		if stale[i] != nil {
+			held = append(held, stale[i])
+		}
+	}
+	return missing, held
+}
+
+// getBatchFromArchive fetches past items with one archive call per distinct
+// location, spanning the dates requested for it.
+func (wsvc *WeatherService) getBatchFromArchive(results []BatchItemResult, queries []weatherQuery, indexes []int, fields []string) {
+	byLocation := make(map[Location][]int)
+	var locations []Location
+	for _, i := range indexes {
+		location := Location{Lat: queries[i].Lat, Lon: queries[i].Lon}
+		if _, ok := byLocation[location]; !ok {
+			locations = append(locations, location)
+		}
+		byLocation[location] = append(byLocation[location], i)
+	}
+
+	for _, location := range locations {
+		items := byLocation[location]
+		start, end := queries[items[0]].Date, queries[items[0]].Date
+		for _, i := range items {
+			start = min(start, queries[i].Date)
+			end = max(end, queries[i].Date)
+		}
+
+		fm, err := wsvc.getArchive(location.Lat, location.Lon, start, end, fields)
+		for _, i := range items {
+			if err != nil {
+				problem := asServiceError(err).Problem()
+				results[i].Error = &problem
+				continue
+			}
+
+			forecast, ok := fm[queries[i].Date]
+			if !ok {
+				errId := logging.LogError(fmt.Errorf("archive data not found"), map[string]interface{}{"lat": queries[i].Lat, "lon": queries[i].Lon, "date": queries[i].Date})
+				problem := archiveNotAvailableError(errId, queries[i].Date).Problem()
+				results[i].Error = &problem
+				continue
+			}
+
+			wsr := ForecastToWeatherServiceResponse(queries[i].Date, forecast)
+			wsr.Timezone = cmp.Or(wsr.Timezone, queries[i].Timezone)
+			results[i].Weather = &wsr
+		}
+	}
+}
+
+// getBatchFromForecastClient fetches every distinct missing location with a
+// single GetForecasts call and fills the matching results.
+func (wsvc *WeatherService) getBatchFromForecastClient(results []BatchItemResult, queries []weatherQuery, indexes []int, fields []string) {
+	locationIndex := make(map[Location]int)
+	var locations []Location
+	for _, i := range indexes {
+		location := Location{Lat: queries[i].Lat, Lon: queries[i].Lon}
+		if _, ok := locationIndex[location]; !ok {
+			locationIndex[location] = len(locations)
+			locations = append(locations, location)
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"items":     len(indexes),
+		"locations": len(locations),
+	}).Info("Did not find all batch items from cache, will fetch from third party provider")
+	forecasts, err := wsvc.WeatherClient.GetForecasts(locations, fields...)
+	if err == nil && len(forecasts) != len(locations) {
+		err = fmt.Errorf("got %d forecasts for %d locations", len(forecasts), len(locations))
+	}
+	if err != nil {
+		problem := weatherProviderError(logging.LogError(err, map[string]interface{}{"locations": len(locations)})).Problem()
+		for _, i := range indexes {
+			results[i].Error = &problem
+		}
+		return
+	}
+
+	for n, fm := range forecasts {
+		forecasts[n] = wsvc.fromProvider(fm, locations[n].Lat, locations[n].Lon)
+		batchPutToCacheStore(wsvc, forecasts[n])
+	}
+
+	for _, i := range indexes {
+		fm := forecasts[locationIndex[Location{Lat: queries[i].Lat, Lon: queries[i].Lon}]]
+		forecast, ok := fm[queries[i].Date]
+		if !ok {
+			errId := logging.LogError(fmt.Errorf("forecast not found"), map[string]interface{}{"lat": queries[i].Lat, "lon": queries[i].Lon, "date": queries[i].Date})
+			problem := forecastNotFoundError(errId).Problem()
+			results[i].Error = &problem
+			continue
+		}
+
+		wsr := ForecastToWeatherServiceResponse(queries[i].Date, forecast)
+		wsr.Timezone = cmp.Or(wsr.Timezone, queries[i].Timezone)
+		results[i].Weather = &wsr
+	}
+}
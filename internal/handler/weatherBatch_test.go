@@ -0,0 +1,127 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+	"weather-service/helper/mockutil"
+	"weather-service/internal/handler"
+	"weather-service/internal/handler/mocks"
+)
+
+var _ = Describe("WeatherBatch", mockutil.Mockable(func(helper *mockutil.Helper) {
+	var (
+		mockCache          *mocks.MockCache
+		mockForecastClient *mocks.MockForecastClient
+		ws                 *handler.WeatherService
+	)
+
+	today := time.Now().UTC().Format("2006-01-02")
+
+	BeforeEach(func() {
+		mockCache = mocks.NewMockCache(helper.Controller())
+		mockForecastClient = mocks.NewMockForecastClient(helper.Controller())
+		ws = handler.NewWeatherService(mockForecastClient, mockCache)
+	})
+
+	When("some items fail", func() {
+		BeforeEach(func() {
+			cachedKey := fmt.Sprintf("42.00_23.00_%s", today)
+			mockCache.EXPECT().Get(cachedKey).Return(&handler.CachedWeather{Key: cachedKey, TempMax: 23}, nil).Times(1)
+			mockCache.EXPECT().Get(fmt.Sprintf("43.00_24.00_%s", today)).Return(nil, nil).Times(1)
+			mockForecastClient.EXPECT().GetForecasts([]handler.Location{{Lat: "43.00", Lon: "24.00"}}).Return(nil, fmt.Errorf("some error")).Times(1)
+		})
+
+		It("should return per-item results and errors", func() {
+			body := fmt.Sprintf(`[{"lat":42.0,"lon":23.0,"date":"%s"},{"lat":43.0,"lon":24.0,"date":"%s"},{"lat":44.0,"date":"%s"}]`, today, today, today)
+			res := ws.Handle(context.TODO(), handler.Request{Method: "POST", Path: "/weather/batch", Body: body})
+			Expect(res.StatusCode).To(Equal(200))
+
+			var results []handler.BatchItemResult
+			Expect(json.Unmarshal([]byte(res.Body), &results)).To(Succeed())
+			Expect(results).To(HaveLen(3))
+
+			Expect(results[0].Error).To(BeNil())
+			Expect(results[0].Weather.Temperature).To(Equal(23.0))
+
+			Expect(results[1].Weather).To(BeNil())
+			Expect(results[1].Error.Status).To(Equal(500))
+			Expect(results[1].Error.Detail).To(Equal("Weather api error"))
+			Expect(results[1].Error.ErrorId).ToNot(BeEmpty())
+
+			Expect(results[2].Error.Status).To(Equal(400))
+			Expect(results[2].Error.Detail).To(Equal("Missing lat/lon"))
+		})
+	})
+
+	When("several items miss the cache", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(gomock.Any()).Return(nil, nil).Times(3)
+			mockForecastClient.EXPECT().GetForecasts([]handler.Location{
+				{Lat: "42.00", Lon: "23.00"},
+				{Lat: "43.00", Lon: "24.00"},
+			}).Return([]handler.ForecastMap{
+				{today: handler.Forecast{Latitude: "42.0", Longitude: "23.0", Temp2max: 21}},
+				{today: handler.Forecast{Latitude: "43.0", Longitude: "24.0", Temp2max: 22}},
+			}, nil).Times(1)
+			mockCache.EXPECT().Put(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+		})
+
+		It("should fetch every distinct location in one call", func() {
+			body := fmt.Sprintf(`[{"lat":42.0,"lon":23.0,"date":"%s"},{"lat":43.0,"lon":24.0,"date":"%s"},{"lat":42.0,"lon":23.0}]`, today, today)
+			res := ws.Handle(context.TODO(), handler.Request{Method: "POST", Path: "/weather/batch", Body: body})
+			Expect(res.StatusCode).To(Equal(200))
+
+			var results []handler.BatchItemResult
+			Expect(json.Unmarshal([]byte(res.Body), &results)).To(Succeed())
+			Expect(results).To(HaveLen(3))
+			Expect(results[0].Weather.Temperature).To(Equal(21.0))
+			Expect(results[1].Weather.Temperature).To(Equal(22.0))
+			Expect(results[2].Weather.Temperature).To(Equal(21.0))
+		})
+	})
+
+	When("many items are requested", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().Get(gomock.Any()).Return(&handler.CachedWeather{Key: fmt.Sprintf("42.00_23.00_%s", today)}, nil).Times(50)
+		})
+
+		It("should keep items in request order", func() {
+			items := make([]map[string]interface{}, 50)
+			for i := range items {
+				items[i] = map[string]interface{}{"lat": json.Number(fmt.Sprintf("%d.0", i)), "lon": 23.0, "date": today}
+			}
+			body, _ := json.Marshal(items)
+
+			res := ws.Handle(context.TODO(), handler.Request{Method: "POST", Path: "/weather/batch", Body: string(body)})
+			Expect(res.StatusCode).To(Equal(200))
+
+			var results []handler.BatchItemResult
+			Expect(json.Unmarshal([]byte(res.Body), &results)).To(Succeed())
+			Expect(results).To(HaveLen(50))
+			for i, result := range results {
+				Expect(result.Lat).To(Equal(fmt.Sprintf("%d.0", i)))
+			}
+		})
+	})
+
+	When("body is not a list", func() {
+		It("should return error response", func() {
+			res := ws.Handle(context.TODO(), handler.Request{Method: "POST", Path: "/weather/batch", Body: `{"lat":42.0}`})
+			Expect(res.StatusCode).To(Equal(400))
+			Expect(res.Body).To(ContainSubstring("Invalid batch body"))
+		})
+	})
+
+	When("method is not POST", func() {
+		It("should return error response", func() {
+			res := ws.Handle(context.TODO(), handler.Request{Method: "GET", Path: "/weather/batch"})
+			Expect(res.StatusCode).To(Equal(405))
+			Expect(res.Headers).To(HaveKeyWithValue("Allow", "POST"))
+		})
+	})
+}))
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"weather-service/internal/logging"
+)
+
+// handleHourly serves /weather/hourly with the 24 hourly points of a single date.
+func (wsvc *WeatherService) handleHourly(ctx context.Context, req Request) Response {
+	lat := req.QueryParameters["lat"]
+	lon := req.QueryParameters["lon"]
+	date := req.QueryParameters["date"]
+
+	logrus.WithFields(logrus.Fields{
+		"lat":  lat,
+		"lon":  lon,
+		"date": date,
+	}).Info("Going to handle hourly request")
+
+	defaulted := date == ""
+	query, err := wsvc.validateWeatherQuery(lat, lon, date)
+	if err != nil {
+		return errorResponse(err)
+	}
+	lat, lon, date = query.Lat, query.Lon, query.Date
+	if query.Past {
+		return errorResponse(invalidParam("date", fmt.Errorf("Invalid date: Date could not be older than today")))
+	}
+
+	units, err := parseUnits(req.QueryParameters)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	key := hourlyCacheKey(lat, lon, date)
+	if cachedWeather, err := wsvc.WeatherCache.GetHourly(key); err == nil && cachedWeather != nil {
+		logrus.WithFields(logrus.Fields{
+			"key": key,
+		}).Info("Got hourly weather from cache")
+		wsvc.learnTimezone(lat, lon, cachedWeather.Timezone)
+		return respondHourly(units.convertHourly(CachedHourlyDataToHourlyWeatherServiceResponse(*cachedWeather)), query.Timezone)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"key": key,
+	}).Info("Did not find hourly weather from cache, will fetch from third party provider")
+	forecastRes, err := wsvc.WeatherClient.GetHourlyForecast(lat, lon)
+	if err != nil {
+		errId := logging.LogError(err, map[string]interface{}{"lat": lat, "lon": lon})
+		return errorResponse(weatherProviderError(errId))
+	}
+	var tz string
+	for date, hours := range forecastRes {
+		forecastRes[date] = withHourlyLocation(hours, lat, lon)
+		if len(hours) > 0 {
+			tz = hours[0].Timezone
+		}
+	}
+	wsvc.learnTimezone(lat, lon, tz)
+	if defaulted && tz != "" && tz != query.Timezone {
+		// today in the timezone the provider reported
+		date = todayDate(wsvc.locationTimezone(lat, lon))
+	}
+	hours, ok := forecastRes[date]
+	if !ok || len(hours) == 0 {
+		errId := logging.LogError(fmt.Errorf("hourly forecast not found"), map[string]interface{}{"lat": lat, "lon": lon, "date": date})
+		return errorResponse(forecastNotFoundError(errId))
+	}
+
+	ttls := batchPutHourlyToCacheStore(wsvc, forecastRes)
+
+	data := HourlyForecastsToCachedData(hours)
+	data.Key = hourlyCacheKey(hours[0].Latitude, hours[0].Longitude, date)
+	data.TTL = ttls[date]
+	return respondHourly(units.convertHourly(CachedHourlyDataToHourlyWeatherServiceResponse(*data)), query.Timezone)
+}
+
+func hourlyCacheKey(lat, lon, date string) string {
+	return fmt.Sprintf("%s_%s_%s_hourly", lat, lon, date)
+}
+
+// batchPutHourlyToCacheStore caches every day of fm and returns the TTL of
+// the items that were stored, by date.
+func batchPutHourlyToCacheStore(wsvc *WeatherService, fm HourlyForecastMap) map[string]int64 {
+	ttls := make(map[string]int64, len(fm))
+	for date, hours := range fm {
+		if len(hours) == 0 {
+			continue
+		}
+		keyStore := hourlyCacheKey(hours[0].Latitude, hours[0].Longitude, date)
+		data := HourlyForecastsToCachedData(hours)
+		err := wsvc.WeatherCache.PutHourly(keyStore, data)
+		if err != nil {
+			logging.LogError(err, map[string]interface{}{"key": keyStore})
+			continue
+		}
+		ttls[date] = data.TTL
+	}
+	return ttls
+}
+
+func respondHourly(hwsr HourlyWeatherServiceResponse, timezone string) Response {
+	hwsr.Timezone = cmp.Or(hwsr.Timezone, timezone)
+	return withCacheControl(respond(hwsr), hwsr.expiresAt)
+}
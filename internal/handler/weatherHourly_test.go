@@ -0,0 +1,112 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+	"weather-service/helper/mockutil"
+	"weather-service/internal/handler"
+	"weather-service/internal/handler/mocks"
+)
+
+var _ = Describe("WeatherHourly", mockutil.Mockable(func(helper *mockutil.Helper) {
+	var (
+		mockCache          *mocks.MockCache
+		mockForecastClient *mocks.MockForecastClient
+		ws                 *handler.WeatherService
+	)
+
+	today := time.Now().UTC().Format("2006-01-02")
+	key := fmt.Sprintf("42.00_23.00_%s_hourly", today)
+
+	BeforeEach(func() {
+		mockCache = mocks.NewMockCache(helper.Controller())
+		mockForecastClient = mocks.NewMockForecastClient(helper.Controller())
+		ws = handler.NewWeatherService(mockForecastClient, mockCache)
+	})
+
+	req := handler.Request{
+		Path: "/weather/hourly",
+		QueryParameters: map[string]string{
+			"lat":  "42.0",
+			"lon":  "23.0",
+			"date": today,
+		},
+	}
+
+	When("cache return data", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().GetHourly(key).Return(&handler.CachedHourlyWeather{
+				Key: key,
+				Hours: []handler.CachedHour{
+					{Time: today + "T00:00", Temp: 18.5, WindSpeed: 4.2, CloudCover: 20},
+					{Time: today + "T01:00", Temp: 17.9, WindSpeed: 3.8, CloudCover: 25},
+				},
+			}, nil).Times(1)
+			mockForecastClient.EXPECT().GetHourlyForecast(gomock.Any(), gomock.Any()).Times(0)
+		})
+
+		It("should return hours from cache", func() {
+			res := ws.Handle(context.TODO(), req)
+			Expect(res.StatusCode).To(Equal(200))
+
+			var hourly handler.HourlyWeatherServiceResponse
+			Expect(json.Unmarshal([]byte(res.Body), &hourly)).To(Succeed())
+			Expect(hourly.Date).To(Equal(today))
+			Expect(hourly.Latitude).To(Equal("42.00"))
+			Expect(hourly.Hours).To(HaveLen(2))
+			Expect(hourly.Hours[1].Temperature).To(Equal(17.9))
+			Expect(hourly.Hours[1].CloudCover).To(Equal(25.0))
+		})
+	})
+
+	When("cache does not return data", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().GetHourly(key).Return(nil, nil).Times(1)
+			mockForecastClient.EXPECT().GetHourlyForecast("42.00", "23.00").Return(handler.HourlyForecastMap{
+				today: {
+					{Latitude: "42.0", Longitude: "23.0", Time: today + "T00:00", Temp2m: 18.5, Precipitation: 0.2},
+				},
+			}, nil).Times(1)
+			mockCache.EXPECT().PutHourly(key, gomock.Any()).Return(nil).Times(1)
+		})
+
+		It("should return hours from forecast client", func() {
+			res := ws.Handle(context.TODO(), req)
+			Expect(res.StatusCode).To(Equal(200))
+
+			var hourly handler.HourlyWeatherServiceResponse
+			Expect(json.Unmarshal([]byte(res.Body), &hourly)).To(Succeed())
+			Expect(hourly.Hours).To(HaveLen(1))
+			Expect(hourly.Hours[0].Precipitation).To(Equal(0.2))
+		})
+	})
+
+	When("forecast client returns error", func() {
+		BeforeEach(func() {
+			mockCache.EXPECT().GetHourly(key).Return(nil, nil).Times(1)
+			mockForecastClient.EXPECT().GetHourlyForecast(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("some error")).Times(1)
+		})
+
+		It("should return error response", func() {
+			res := ws.Handle(context.TODO(), req)
+			Expect(res.StatusCode).To(Equal(500))
+			Expect(res.Body).To(ContainSubstring("Weather api error"))
+		})
+	})
+
+	When("latitude or longitude is not provided", func() {
+		It("should return error response", func() {
+			res := ws.Handle(context.TODO(), handler.Request{
+				Path:            "/weather/hourly",
+				QueryParameters: map[string]string{"lat": "42.0"},
+			})
+			Expect(res.StatusCode).To(Equal(400))
+			Expect(res.Body).To(ContainSubstring("Missing lat/lon"))
+		})
+	})
+}))
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"strconv"
+	"time"
+	"weather-service/internal/logging"
+)
+
+// isRangeRequest reports whether req asks for several days instead of a single date.
+func isRangeRequest(req Request) bool {
+	return req.QueryParameters["start"] != "" || req.QueryParameters["end"] != "" || req.QueryParameters["days"] != ""
+}
+
+// handleRange serves start/end or days=N queries. Days found in the cache are
+// served from it and the provider is called at most once for the rest.
+func (wsvc *WeatherService) handleRange(ctx context.Context, req Request) Response {
+	lat := req.QueryParameters["lat"]
+	lon := req.QueryParameters["lon"]
+
+	logrus.WithFields(logrus.Fields{
+		"lat":   lat,
+		"lon":   lon,
+		"start": req.QueryParameters["start"],
+		"end":   req.QueryParameters["end"],
+		"days":  req.QueryParameters["days"],
+	}).Info("Going to handle range request")
+
+	lat, lon, err := wsvc.normalizeLocation(lat, lon)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	enc, err := negotiateEncoder(req)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	p, err := parsePresentation(req.QueryParameters)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	loc := wsvc.locationTimezone(lat, lon)
+	dates, err := wsvc.rangeDates(req.QueryParameters, loc)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	archiveEnd := wsvc.archiveEnd(loc)
+	results := make([]WeatherServiceResponse, len(dates))
+	var missing, past []int
+	var stale []*CachedWeather
+	for i, date := range dates {
+		key := fmt.Sprintf("%s_%s_%s", lat, lon, date)
+		cachedWeather, err := wsvc.WeatherCache.Get(key)
+		if err == nil && cachedWeather != nil && (date >= archiveEnd || archived(cachedWeather)) {
+			if coversFields(cachedWeather, p.fields, date < archiveEnd) {
+				wsvc.learnTimezone(lat, lon, cachedWeather.Timezone)
+				results[i] = p.present(CachedDataToWeatherServiceResponse(*cachedWeather))
+				results[i].Timezone = cmp.Or(results[i].Timezone, loc.String())
+				if date < archiveEnd {
+					results[i].expiresAt = historicalExpiresAt()
+				}
+				continue
+			}
+			stale = append(stale, cachedWeather)
+		}
+		if date < archiveEnd {
+			past = append(past, i)
+			continue
+		}
+		missing = append(missing, i)
+	}
+
+	fetch := fetchFields(p.fields, stale...)
+
+	if len(past) > 0 {
+		archiveRes, err := wsvc.getArchive(lat, lon, dates[past[0]], dates[past[len(past)-1]], fetch)
+		if err != nil {
+			return errorResponse(err)
+		}
+		for _, i := range past {
+			forecast, ok := archiveRes[dates[i]]
+			if !ok {
+				// left out, the archive runs behind and may not have it yet
+				logging.LogError(fmt.Errorf("archive data not found"), map[string]interface{}{"lat": lat, "lon": lon, "date": dates[i]})
+				continue
+			}
+			results[i] = p.present(ForecastToWeatherServiceResponse(dates[i], forecast))
+			results[i].Timezone = cmp.Or(results[i].Timezone, loc.String())
+			results[i].expiresAt = historicalExpiresAt()
+		}
+	}
+
+	if len(missing) == 0 {
+		logrus.WithFields(logrus.Fields{
+			"lat": lat,
+			"lon": lon,
+		}).Info("Got all days from cache")
+		return respondEncoded(enc, withDate(results), false)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"lat":     lat,
+		"lon":     lon,
+		"missing": len(missing),
+	}).Info("Did not find all days in cache, will fetch from third party provider")
+	forecastRes, err := wsvc.WeatherClient.GetForecast(lat, lon, fetch...)
+	if err != nil {
+		errId := logging.LogError(err, map[string]interface{}{"lat": lat, "lon": lon})
+		return errorResponse(weatherProviderError(errId))
+	}
+	forecastRes = wsvc.fromProvider(forecastRes, lat, lon)
+
+	for _, i := range missing {
+		date := dates[i]
+		forecast, ok := forecastRes[date]
+		if !ok {
+			errId := logging.LogError(fmt.Errorf("forecast not found"), map[string]interface{}{"lat": lat, "lon": lon, "date": date})
+			return errorResponse(forecastNotFoundError(errId))
+		}
+		results[i] = p.present(ForecastToWeatherServiceResponse(date, forecast))
+		results[i].Timezone = cmp.Or(results[i].Timezone, loc.String())
+	}
+
+	ttls := batchPutToCacheStore(wsvc, forecastRes)
+	for _, i := range missing {
+		results[i].expiresAt = ttls[dates[i]]
+	}
+
+	return respondEncoded(enc, withDate(results), false)
+}
+
+// withDate returns the days of results that were found, leaving out the ones
+// that are still unset.
+func withDate(results []WeatherServiceResponse) []WeatherServiceResponse {
+	days := make([]WeatherServiceResponse, 0, len(results))
+	for _, wsr := range results {
+		if wsr.Date != "" {
+			days = append(days, wsr)
+		}
+	}
+	return days
+}
+
+// rangeDates resolves start/end/days query parameters into the list of dates
+// to serve. start defaults to today in loc, end defaults to start unless days is set.
+// Every date is checked with parseForecastDate.
+func (wsvc *WeatherService) rangeDates(query map[string]string, loc *time.Location) ([]string, error) {
+	start := query["start"]
+	if start == "" {
+		start = todayDate(loc)
+	}
+
+	startDate, err := wsvc.parseForecastDate(start, loc)
+	if err != nil {
+		return nil, invalidParam("start", err)
+	}
+
+	endDate := startDate
+	switch {
+	case query["end"] != "" && query["days"] != "":
+		svcErr := invalidParam("end", fmt.Errorf("Invalid range: end and days could not be used together"))
+		svcErr.InvalidParams = append(svcErr.InvalidParams, InvalidParam{Name: "days", Reason: svcErr.Message})
+		return nil, svcErr
+	case query["end"] != "":
+		if endDate, err = wsvc.parseForecastDate(query["end"], loc); err != nil {
+			return nil, invalidParam("end", err)
+		}
+	case query["days"] != "":
+		days, err := strconv.Atoi(query["days"])
+		if err != nil || days < 1 {
+			return nil, invalidParam("days", fmt.Errorf("Invalid days: must be a positive number"))
+		}
+		if endDate, err = wsvc.parseForecastDate(startDate.AddDate(0, 0, days-1).Format(dateLayout), loc); err != nil {
+			return nil, invalidParam("days", err)
+		}
+	}
+
+	if endDate.Before(startDate) {
+		return nil, invalidParam("end", fmt.Errorf("Invalid range: end could not be before start"))
+	}
+
+	var dates []string
+	for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d.Format(dateLayout))
+	}
+	return dates, nil
+}
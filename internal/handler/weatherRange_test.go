@@ -0,0 +1,133 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+	"weather-service/helper/mockutil"
+	"weather-service/internal/handler"
+	"weather-service/internal/handler/mocks"
+)
+
+var _ = Describe("WeatherRange", mockutil.Mockable(func(helper *mockutil.Helper) {
+	var (
+		mockCache          *mocks.MockCache
+		mockForecastClient *mocks.MockForecastClient
+		ws                 *handler.WeatherService
+	)
+
+	today := time.Now().UTC().Format("2006-01-02")
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	dayAfter := time.Now().UTC().AddDate(0, 0, 2).Format("2006-01-02")
+
+	BeforeEach(func() {
+		mockCache = mocks.NewMockCache(helper.Controller())
+		mockForecastClient = mocks.NewMockForecastClient(helper.Controller())
+		ws = handler.NewWeatherService(mockForecastClient, mockCache)
+	})
+
+	Context("Right query params", func() {
+		When("all days are cached", func() {
+			BeforeEach(func() {
+				for _, date := range []string{today, tomorrow} {
+					key := fmt.Sprintf("42.00_23.00_%s", date)
+					mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{Key: key, TempMax: 20}, nil).Times(1)
+				}
+				mockForecastClient.EXPECT().GetForecast(gomock.Any(), gomock.Any()).Times(0)
+			})
+
+			It("should return every day from cache", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "start": today, "end": tomorrow},
+				})
+				Expect(res.StatusCode).To(Equal(200))
+
+				var days []handler.WeatherServiceResponse
+				Expect(json.Unmarshal([]byte(res.Body), &days)).To(Succeed())
+				Expect(days).To(HaveLen(2))
+				Expect(days[0].Date).To(Equal(today))
+				Expect(days[1].Date).To(Equal(tomorrow))
+			})
+		})
+
+		When("some days are missing from cache", func() {
+			BeforeEach(func() {
+				todayKey := fmt.Sprintf("42.00_23.00_%s", today)
+				mockCache.EXPECT().Get(todayKey).Return(&handler.CachedWeather{Key: todayKey, TempMax: 20}, nil).Times(1)
+				mockCache.EXPECT().Get(fmt.Sprintf("42.00_23.00_%s", tomorrow)).Return(nil, nil).Times(1)
+				mockCache.EXPECT().Get(fmt.Sprintf("42.00_23.00_%s", dayAfter)).Return(nil, nil).Times(1)
+				mockForecastClient.EXPECT().GetForecast("42.00", "23.00").Return(handler.ForecastMap{
+					today:    handler.Forecast{Latitude: "42.0", Longitude: "23.0", Temp2max: 21},
+					tomorrow: handler.Forecast{Latitude: "42.0", Longitude: "23.0", Temp2max: 22},
+					dayAfter: handler.Forecast{Latitude: "42.0", Longitude: "23.0", Temp2max: 23},
+				}, nil).Times(1)
+				mockCache.EXPECT().Put(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+			})
+
+			It("should fetch the provider once and merge results", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "days": "3"},
+				})
+				Expect(res.StatusCode).To(Equal(200))
+
+				var days []handler.WeatherServiceResponse
+				Expect(json.Unmarshal([]byte(res.Body), &days)).To(Succeed())
+				Expect(days).To(HaveLen(3))
+				Expect(days[0].Temperature).To(Equal(20.0))
+				Expect(days[1].Temperature).To(Equal(22.0))
+				Expect(days[2].Temperature).To(Equal(23.0))
+			})
+		})
+
+		When("forecast client returns error", func() {
+			BeforeEach(func() {
+				mockCache.EXPECT().Get(gomock.Any()).Return(nil, nil).Times(1)
+				mockForecastClient.EXPECT().GetForecast(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("some error")).Times(1)
+			})
+
+			It("should return error response", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "days": "1"},
+				})
+				Expect(res.StatusCode).To(Equal(500))
+				Expect(res.Body).To(ContainSubstring("Weather api error"))
+			})
+		})
+	})
+
+	Context("Wrong query params", func() {
+		When("end is before start", func() {
+			It("should return error response", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "start": tomorrow, "end": today},
+				})
+				Expect(res.StatusCode).To(Equal(400))
+				Expect(res.Body).To(ContainSubstring("end could not be before start"))
+			})
+		})
+
+		When("days is not a positive number", func() {
+			It("should return error response", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "days": "0"},
+				})
+				Expect(res.StatusCode).To(Equal(400))
+				Expect(res.Body).To(ContainSubstring("Invalid days"))
+			})
+		})
+
+		When("range goes past the forecast window", func() {
+			It("should return error response", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "days": "14"},
+				})
+				Expect(res.StatusCode).To(Equal(400))
+				Expect(res.Body).To(ContainSubstring("Date could not be 7 day from today"))
+			})
+		})
+	})
+}))
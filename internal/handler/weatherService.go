@@ -1,43 +1,113 @@
 package handler
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/aws/aws-lambda-go/events"
 	"github.com/sirupsen/logrus"
 	"net/http"
-	"time"
+	"strings"
 	"weather-service/internal/logging"
 )
 
 //go:generate mockgen --source=weatherService.go --destination mocks/weatherService.go --package mocks
 
 type ForecastClient interface {
-	GetForecast(lat, long string) (ForecastMap, error)
+	// GetForecast fetches DefaultVariables and the given extra fields.
+	GetForecast(lat, long string, fields ...string) (ForecastMap, error)
+	GetForecasts(locations []Location, fields ...string) ([]ForecastMap, error)
+	GetHourlyForecast(lat, long string) (HourlyForecastMap, error)
 }
 
 type Cache interface {
 	Put(key string, weather *CachedWeather) error
+	// PutPermanent stores weather without expiry, for days that do not change.
+	PutPermanent(key string, weather *CachedWeather) error
 	Get(key string) (*CachedWeather, error)
+	PutHourly(key string, weather *CachedHourlyWeather) error
+	GetHourly(key string) (*CachedHourlyWeather, error)
+}
+
+// Geocoder resolves place names to coordinates.
+type Geocoder interface {
+	Lookup(name, country string) []Place
 }
 
 type WeatherService struct {
 	WeatherClient ForecastClient
 	WeatherCache  Cache
+	// Geocoder is optional, without it q=<place name> queries are rejected.
+	Geocoder Geocoder
+	// CoordinatePrecision is the number of decimals coordinates are rounded to.
+	CoordinatePrecision int
+	// CoordinateGrid, when set, snaps coordinates to multiples of it (in degrees)
+	// before rounding, e.g. to match the provider's model grid.
+	CoordinateGrid float64
+	// Timezones is optional, without it dates are interpreted in UTC until
+	// the provider reports the location's timezone.
+	Timezones TimezoneLocator
+	// ArchiveClient is optional, without it dates before today are rejected.
+	ArchiveClient ArchiveClient
+	// ForecastDays is how many days, today included, can be requested. It
+	// should match the horizon the ForecastClient asks the provider for.
+	ForecastDays int
+	// PastDays is how many days before today are served by the ForecastClient
+	// rather than the archive, which runs behind. It should match the past
+	// days the ForecastClient asks the provider for.
+	PastDays int
+
+	// learned holds the timezones the provider reported.
+	learned learnedTimezones
 }
 
 func NewWeatherService(clnt ForecastClient, wc Cache) *WeatherService {
 	return &WeatherService{
-		WeatherClient: clnt,
-		WeatherCache:  wc,
+		WeatherClient:       clnt,
+		WeatherCache:        wc,
+		CoordinatePrecision: DefaultCoordinatePrecision,
+		ForecastDays:        DefaultForecastDays,
 	}
 }
 
-func (wsvc *WeatherService) HandleRequest(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	lat := req.QueryStringParameters["lat"]
-	lon := req.QueryStringParameters["lon"]
-	date := req.QueryStringParameters["date"]
+// Handle serves a weather request independently of the transport it came from.
+func (wsvc *WeatherService) Handle(ctx context.Context, req Request) Response {
+	return conditionalResponse(req, wsvc.route(ctx, req))
+}
+
+func (wsvc *WeatherService) route(ctx context.Context, req Request) Response {
+	req, err := wsvc.resolvePlace(req)
+	if err != nil {
+		var ambiguous *ambiguousPlaceError
+		if errors.As(err, &ambiguous) {
+			return respondWithStatus(http.StatusMultipleChoices, ambiguous)
+		}
+		return errorResponse(err)
+	}
+
+	switch {
+	case strings.HasSuffix(req.Path, "/weather/hourly"):
+		return wsvc.handleHourly(ctx, req)
+	case strings.HasSuffix(req.Path, "/weather/batch"):
+		return wsvc.handleBatch(ctx, req)
+	case strings.HasSuffix(req.Path, "/weather/calendar"):
+		return wsvc.handleRange(ctx, calendarRequest(req, wsvc.forecastDays()))
+	case strings.HasSuffix(req.Path, "/weather/chart.svg"):
+		return wsvc.handleRange(ctx, chartRequest(req, wsvc.forecastDays()))
+	case terminalFormat(req) != "":
+		return wsvc.handleRange(ctx, terminalRequest(req, terminalFormat(req), wsvc.forecastDays()))
+	case isRangeRequest(req):
+		return wsvc.handleRange(ctx, req)
+	default:
+		return wsvc.handleWeather(ctx, req)
+	}
+}
+
+func (wsvc *WeatherService) handleWeather(ctx context.Context, req Request) Response {
+	lat := req.QueryParameters["lat"]
+	lon := req.QueryParameters["lon"]
+	date := req.QueryParameters["date"]
 
 	logrus.WithFields(logrus.Fields{
 		"lat":  lat,
@@ -45,84 +115,159 @@ func (wsvc *WeatherService) HandleRequest(ctx context.Context, req events.APIGat
 		"date": date,
 	}).Info("Going to handle request")
 
-	if lat == "" || lon == "" {
-		return events.APIGatewayProxyResponse{StatusCode: 400, Body: "Missing lat/lon"}, nil
+	enc, err := negotiateEncoder(req)
+	if err != nil {
+		return errorResponse(err)
 	}
 
-	if date == "" {
-		date = time.Now().Format("2006-01-02")
+	p, err := parsePresentation(req.QueryParameters)
+	if err != nil {
+		return errorResponse(err)
 	}
 
-	parsedDate, err := time.Parse("2006-01-02", date)
+	wsr, err := wsvc.getWeather(lat, lon, date, p.fields)
 	if err != nil {
-		return events.APIGatewayProxyResponse{StatusCode: 400, Body: "Invalid date"}, nil
+		return errorResponse(err)
 	}
 
-	today := time.Now().Truncate(24 * time.Hour)
-	if parsedDate.Before(today) {
-		return events.APIGatewayProxyResponse{StatusCode: 400, Body: "Invalid date: Date could not be older than today"}, nil
-	}
+	return respondEncoded(enc, []WeatherServiceResponse{p.present(wsr)}, true)
+}
 
-	sevenDaysLater := time.Now().Add(7 * 24 * time.Hour)
-	if parsedDate.After(sevenDaysLater) {
-		return events.APIGatewayProxyResponse{StatusCode: 400, Body: "Invalid date: Date could not be 7 day from today"}, nil
+// getWeather resolves the weather of a single location and date through the
+// cache, falling back to the forecast client when the cache misses any of fields.
+func (wsvc *WeatherService) getWeather(lat, lon, date string, fields []string) (WeatherServiceResponse, error) {
+	defaulted := date == ""
+	query, err := wsvc.validateWeatherQuery(lat, lon, date)
+	if err != nil {
+		return WeatherServiceResponse{}, err
 	}
+	lat, lon, date = query.Lat, query.Lon, query.Date
 
 	key := fmt.Sprintf("%s_%s_%s", lat, lon, date)
-	if cachedWeather, err := wsvc.WeatherCache.Get(key); err == nil && cachedWeather != nil {
+	cachedWeather, err := wsvc.WeatherCache.Get(key)
+	if err != nil || (cachedWeather != nil && query.Historical && !archived(cachedWeather)) {
+		cachedWeather = nil
+	}
+	if cachedWeather != nil && coversFields(cachedWeather, fields, query.Historical) {
+		wsvc.learnTimezone(lat, lon, cachedWeather.Timezone)
+		if tz := cachedWeather.Timezone; defaulted && tz != "" && tz != query.Timezone {
+			// today was resolved in another timezone than the item's
+			if today := todayDate(wsvc.locationTimezone(lat, lon)); today != date {
+				return wsvc.getWeather(lat, lon, today, fields)
+			}
+		}
+
 		logrus.WithFields(logrus.Fields{
 			"key": key,
 		}).Info("Got weather from cache")
-		return respond(CachedDataToWeatherServiceResponse(*cachedWeather))
+		wsr := CachedDataToWeatherServiceResponse(*cachedWeather)
+		wsr.Timezone = cmp.Or(wsr.Timezone, query.Timezone)
+		if query.Historical {
+			wsr.expiresAt = historicalExpiresAt()
+		}
+		return wsr, nil
+	}
+
+	if cachedWeather != nil {
+		fields = fetchFields(fields, cachedWeather)
+	}
+
+	if query.Historical {
+		return wsvc.getHistoricalWeather(query, fields)
 	}
 
 	logrus.WithFields(logrus.Fields{
 		"key": key,
 	}).Info("Did not find weather from cache, will fetch from third party provider")
-	forecastRes, err := wsvc.WeatherClient.GetForecast(lat, lon)
+	forecastRes, err := wsvc.WeatherClient.GetForecast(lat, lon, fields...)
 	if err != nil {
 		errId := logging.LogError(err, map[string]interface{}{"lat": lat, "lon": lon})
-		return events.APIGatewayProxyResponse{StatusCode: 500, Body: fmt.Sprintf("[%s] Weather api error", errId)}, nil
+		return WeatherServiceResponse{}, weatherProviderError(errId)
+	}
+	forecastRes = wsvc.fromProvider(forecastRes, lat, lon)
+	if tz := providerTimezone(forecastRes); defaulted && tz != "" && tz != query.Timezone {
+		// today in the timezone the provider reported
+		date = todayDate(wsvc.locationTimezone(lat, lon))
 	}
 	if _, ok := forecastRes[date]; !ok {
-		errId := logging.LogError(err, map[string]interface{}{"lat": lat, "lon": lon, "date": date})
-		return events.APIGatewayProxyResponse{StatusCode: 404, Body: fmt.Sprintf("[%s] Weather forecast not found for this date", errId)}, nil
+		errId := logging.LogError(fmt.Errorf("forecast not found"), map[string]interface{}{"lat": lat, "lon": lon, "date": date})
+		return WeatherServiceResponse{}, forecastNotFoundError(errId)
+	}
+
+	wsr := ForecastToWeatherServiceResponse(date, forecastRes[date])
+	wsr.Timezone = cmp.Or(wsr.Timezone, query.Timezone)
+
+	ttls := batchPutToCacheStore(wsvc, forecastRes)
+	wsr.expiresAt = ttls[date]
+
+	return wsr, nil
+}
+
+// weatherQuery is a validated single day lookup, with normalised coordinates
+// and the date resolved in the location's timezone.
+type weatherQuery struct {
+	Lat      string
+	Lon      string
+	Date     string
+	Timezone string
+	// Past is set for dates before today.
+	Past bool
+	// Historical is set for past dates the archive has, PastDays or more
+	// before today, which are served from it.
+	Historical bool
+}
+
+// validateWeatherQuery checks the parameters of a single day lookup,
+// normalising the coordinates and defaulting the date to today.
+func (wsvc *WeatherService) validateWeatherQuery(lat, lon, date string) (weatherQuery, error) {
+	lat, lon, err := wsvc.normalizeLocation(lat, lon)
+	if err != nil {
+		return weatherQuery{}, err
 	}
 
-	wsr := WeatherServiceResponse{date,
-		forecastRes[date].Latitude,
-		forecastRes[date].Longitude,
-		forecastRes[date].Temp2max,
-		forecastRes[date].UvIndexMax,
-		forecastRes[date].PrecipProbability,
+	loc := wsvc.locationTimezone(lat, lon)
+	if date == "" {
+		date = todayDate(loc)
 	}
 
-	batchPutToCacheStore(wsvc, forecastRes)
+	if _, err := wsvc.parseForecastDate(date, loc); err != nil {
+		return weatherQuery{}, invalidParam("date", err)
+	}
 
-	return respond(wsr)
+	return weatherQuery{Lat: lat, Lon: lon, Date: date, Timezone: loc.String(), Past: date < todayDate(loc), Historical: date < wsvc.archiveEnd(loc)}, nil
 }
 
-func batchPutToCacheStore(wsvc *WeatherService, fm ForecastMap) {
+// batchPutToCacheStore caches every day of fm and returns the TTL of the
+// items that were stored, by date.
+func batchPutToCacheStore(wsvc *WeatherService, fm ForecastMap) map[string]int64 {
+	ttls := make(map[string]int64, len(fm))
 	for key, value := range fm {
 		keyStore := fmt.Sprintf("%s_%s_%s", value.Latitude, value.Longitude, key)
 		data := ForecastToCachedData(value)
 		err := wsvc.WeatherCache.Put(keyStore, data)
 		if err != nil {
 			logging.LogError(err, map[string]interface{}{"key": key, "data": data})
+			continue
 		}
+		ttls[key] = data.TTL
 	}
+	return ttls
 }
 
-func respond(w WeatherServiceResponse) (events.APIGatewayProxyResponse, error) {
+func respond(w interface{}) Response {
+	return respondWithStatus(http.StatusOK, w)
+}
+
+func respondWithStatus(status int, w interface{}) Response {
 	wsrBytes, err := json.Marshal(w)
 	if err != nil {
 		errId := logging.LogError(err, map[string]interface{}{"weatherServiceResponse": w})
-		return events.APIGatewayProxyResponse{StatusCode: 400, Body: fmt.Sprintf("[%s] Error while generating response", errId)}, nil
+		return errorResponse(internalError(errId, "Error while generating response"))
 	}
 
-	return events.APIGatewayProxyResponse{
-		StatusCode: http.StatusOK,
+	return Response{
+		StatusCode: status,
 		Body:       string(wsrBytes),
 		Headers:    map[string]string{"Content-Type": "application/json"},
-	}, nil
+	}
 }
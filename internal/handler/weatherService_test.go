@@ -3,10 +3,10 @@ package handler_test
 import (
 	"context"
 	"fmt"
-	"github.com/aws/aws-lambda-go/events"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"regexp"
 	"time"
 	"weather-service/helper/mockutil"
 	"weather-service/internal/handler"
@@ -27,15 +27,17 @@ var _ = Describe("WeatherService", mockutil.Mockable(func(helper *mockutil.Helpe
 	})
 
 	Context("Right query params", func() {
-		today := time.Now().Format("2006-01-02")
+		today := time.Now().UTC().Format("2006-01-02")
+		uvIndex := 3.0
+		rainProbability := 0.0
 		When("cache does not return data", func() {
 			expectedRes := handler.ForecastMap{
 				today: handler.Forecast{
 					Latitude:          "42.0",
 					Longitude:         "23.0",
 					Temp2max:          23,
-					UvIndexMax:        3,
-					PrecipProbability: 0,
+					UvIndexMax:        &uvIndex,
+					PrecipProbability: &rainProbability,
 				},
 			}
 			BeforeEach(func() {
@@ -46,27 +48,98 @@ var _ = Describe("WeatherService", mockutil.Mockable(func(helper *mockutil.Helpe
 
 			It("should return date from forecast client", func() {
 
-				req := events.APIGatewayProxyRequest{
-					QueryStringParameters: map[string]string{
+				req := handler.Request{
+					QueryParameters: map[string]string{
 						"lat":  "42.0",
 						"lon":  "23.0",
 						"date": today,
 					},
 				}
-				res, err := ws.HandleRequest(context.TODO(), req)
-				Expect(err).ToNot(HaveOccurred())
+				res := ws.Handle(context.TODO(), req)
 				Expect(res.StatusCode).To(Equal(200))
-				Expect(res.Body).To(Equal(fmt.Sprintf("{\"date\":\"%s\",\"latitude\":\"42.0\",\"longitude\":\"23.0\",\"temperature\":23,\"uvIndex\":3,\"rainProbability\":0}", today)))
+				Expect(res.Body).To(MatchRegexp(fmt.Sprintf(`^\{"date":"%s","latitude":"42.00","longitude":"23.00","temperature":23,"uvIndex":3,`+regexp.QuoteMeta(moderateUVJSON)+`"rainProbability":0,`+
+					`"sunrise":"%sT[0-9:]+Z","sunset":"%sT[0-9:]+Z","solarNoon":"%sT[0-9:]+Z","daylightDuration":[0-9]+,`+
+					`"summary":"High of 23°C, low chance of rain, moderate UV","units":\{"temperature":"celsius","precipitation":"mm","windSpeed":"kmh"\},"timezone":"UTC"\}$`, today, today, today, today)))
+			})
+		})
+		When("forecast has a weather code", func() {
+			var cached *handler.CachedWeather
+			BeforeEach(func() {
+				code := 61
+				mockCache.EXPECT().Get(gomock.Any()).Return(nil, nil).Times(1)
+				mockForecastClient.EXPECT().GetForecast(gomock.Any(), gomock.Any()).Return(handler.ForecastMap{
+					today: handler.Forecast{Temp2max: 18, PrecipProbability: &rainProbability, WeatherCode: &code},
+				}, nil).Times(1)
+				mockCache.EXPECT().Put(gomock.Any(), gomock.Any()).DoAndReturn(func(_ string, cw *handler.CachedWeather) error {
+					cached = cw
+					return nil
+				}).Times(1)
+			})
+
+			It("should describe the condition and cache only its code", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": today},
+				})
+				Expect(res.StatusCode).To(Equal(200))
+				Expect(res.Body).To(ContainSubstring(`"rainProbability":0,"condition":"Slight rain","conditionCode":61,"icon":"rain","severity":"minor",`))
+				Expect(*cached.ConditionCode).To(Equal(61))
+			})
+
+			It("should describe the condition in the requested language", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": today, "lang": "bg"},
+				})
+				Expect(res.StatusCode).To(Equal(200))
+				Expect(res.Body).To(ContainSubstring(`"condition":"Слаб дъжд","conditionCode":61,"icon":"rain","severity":"minor",`))
+			})
+		})
+		When("the sun has set on the requested day", func() {
+			BeforeEach(func() {
+				code := 2
+				key := fmt.Sprintf("42.00_23.00_%s", today)
+				mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{
+					Key:           key,
+					TempMax:       18,
+					ConditionCode: &code,
+					Sunrise:       today + "T00:00:00Z",
+					Sunset:        today + "T00:00:00Z",
+				}, nil).Times(1)
+			})
+
+			It("should return the night icon", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": today},
+				})
+				Expect(res.StatusCode).To(Equal(200))
+				Expect(res.Body).To(ContainSubstring(`"condition":"Partly cloudy","conditionCode":2,"icon":"partly-cloudy-night","severity":"none",`))
+			})
+		})
+		When("cached item predates weather codes", func() {
+			BeforeEach(func() {
+				key := fmt.Sprintf("42.00_23.00_%s", today)
+				mockCache.EXPECT().Get(key).Return(&handler.CachedWeather{Key: key, TempMax: 18}, nil).Times(1)
+				mockForecastClient.EXPECT().GetForecast("42.00", "23.00", "weather_code").Return(handler.ForecastMap{
+					today: handler.Forecast{Temp2max: 18},
+				}, nil).Times(1)
+				mockCache.EXPECT().Put(key, gomock.Any()).Return(nil).Times(1)
+			})
+
+			It("should refetch it when the code is selected", func() {
+				res := ws.Handle(context.TODO(), handler.Request{
+					QueryParameters: map[string]string{"lat": "42.0", "lon": "23.0", "date": today, "fields": "weather_code"},
+				})
+				Expect(res.StatusCode).To(Equal(200))
+				Expect(res.Body).To(ContainSubstring(`"conditionCode":null,`))
 			})
 		})
 		When("cache return data", func() {
 			BeforeEach(func() {
-				key := fmt.Sprintf("42.0_23.0_%s", today)
+				key := fmt.Sprintf("42.00_23.00_%s", today)
 				expectedCachedResult := &handler.CachedWeather{
 					Key:      key,
 					TempMax:  23.0,
-					UVIndex:  3,
-					RainProb: 0,
+					UVIndex:  &uvIndex,
+					RainProb: &rainProbability,
 					TTL:      1233312,
 				}
 
@@ -76,17 +149,16 @@ var _ = Describe("WeatherService", mockutil.Mockable(func(helper *mockutil.Helpe
 			})
 
 			It("should return date from cache client", func() {
-				req := events.APIGatewayProxyRequest{
-					QueryStringParameters: map[string]string{
+				req := handler.Request{
+					QueryParameters: map[string]string{
 						"lat":  "42.0",
 						"lon":  "23.0",
 						"date": today,
 					},
 				}
-				res, err := ws.HandleRequest(context.TODO(), req)
-				Expect(err).ToNot(HaveOccurred())
+				res := ws.Handle(context.TODO(), req)
 				Expect(res.StatusCode).To(Equal(200))
-				Expect(res.Body).To(Equal(fmt.Sprintf("{\"date\":\"%s\",\"latitude\":\"42.0\",\"longitude\":\"23.0\",\"temperature\":23,\"uvIndex\":3,\"rainProbability\":0}", today)))
+				Expect(res.Body).To(Equal(fmt.Sprintf("{\"date\":\"%s\",\"latitude\":\"42.00\",\"longitude\":\"23.00\",\"temperature\":23,\"uvIndex\":3,"+moderateUVJSON+"\"rainProbability\":0,\"summary\":\"High of 23°C, low chance of rain, moderate UV\",\"units\":{\"temperature\":\"celsius\",\"precipitation\":\"mm\",\"windSpeed\":\"kmh\"},\"timezone\":\"UTC\"}", today)))
 			})
 		})
 
@@ -98,15 +170,14 @@ var _ = Describe("WeatherService", mockutil.Mockable(func(helper *mockutil.Helpe
 			})
 
 			It("should return error response", func() {
-				req := events.APIGatewayProxyRequest{
-					QueryStringParameters: map[string]string{
+				req := handler.Request{
+					QueryParameters: map[string]string{
 						"lat":  "42.0",
 						"lon":  "23.0",
 						"date": today,
 					},
 				}
-				res, err := ws.HandleRequest(context.TODO(), req)
-				Expect(err).ToNot(HaveOccurred())
+				res := ws.Handle(context.TODO(), req)
 				Expect(res.StatusCode).To(Equal(500))
 				Expect(res.Body).To(ContainSubstring("Weather api error"))
 			})
@@ -119,8 +190,8 @@ var _ = Describe("WeatherService", mockutil.Mockable(func(helper *mockutil.Helpe
 						Latitude:          "42.0",
 						Longitude:         "23.0",
 						Temp2max:          23,
-						UvIndexMax:        3,
-						PrecipProbability: 0,
+						UvIndexMax:        &uvIndex,
+						PrecipProbability: &rainProbability,
 					},
 				}
 				mockCache.EXPECT().Get(gomock.Any()).Return(nil, nil).Times(1)
@@ -129,16 +200,15 @@ var _ = Describe("WeatherService", mockutil.Mockable(func(helper *mockutil.Helpe
 			})
 
 			It("should return error response", func() {
-				req := events.APIGatewayProxyRequest{
-					QueryStringParameters: map[string]string{
+				req := handler.Request{
+					QueryParameters: map[string]string{
 						"lat":  "42.0",
 						"lon":  "23.0",
 						"date": today,
 					},
 				}
 
-				resp, err := ws.HandleRequest(context.TODO(), req)
-				Expect(err).ToNot(HaveOccurred())
+				resp := ws.Handle(context.TODO(), req)
 				Expect(resp.StatusCode).To(Equal(404))
 				Expect(resp.Body).To(ContainSubstring("Weather forecast not found for this date"))
 			})
@@ -147,14 +217,13 @@ var _ = Describe("WeatherService", mockutil.Mockable(func(helper *mockutil.Helpe
 	Context("Wrong query params", func() {
 		When("latitude or longitude is not provided", func() {
 			It("should return error response", func() {
-				req := events.APIGatewayProxyRequest{
-					QueryStringParameters: map[string]string{
+				req := handler.Request{
+					QueryParameters: map[string]string{
 						"lon":  "23.0",
 						"date": "2025-07-09",
 					},
 				}
-				resp, err := ws.HandleRequest(context.TODO(), req)
-				Expect(err).ToNot(HaveOccurred())
+				resp := ws.Handle(context.TODO(), req)
 				Expect(resp.StatusCode).To(Equal(400))
 				Expect(resp.Body).To(ContainSubstring("Missing lat/lon"))
 			})
@@ -162,15 +231,14 @@ var _ = Describe("WeatherService", mockutil.Mockable(func(helper *mockutil.Helpe
 
 		When("previous date provided", func() {
 			It("should return error response", func() {
-				req := events.APIGatewayProxyRequest{
-					QueryStringParameters: map[string]string{
+				req := handler.Request{
+					QueryParameters: map[string]string{
 						"lat":  "42.0",
 						"lon":  "23.0",
 						"date": "2025-07-09",
 					},
 				}
-				resp, err := ws.HandleRequest(context.TODO(), req)
-				Expect(err).ToNot(HaveOccurred())
+				resp := ws.Handle(context.TODO(), req)
 				Expect(resp.StatusCode).To(Equal(400))
 				Expect(resp.Body).To(ContainSubstring("Invalid date: Date could not be older than today"))
 			})
@@ -178,15 +246,14 @@ var _ = Describe("WeatherService", mockutil.Mockable(func(helper *mockutil.Helpe
 
 		When("late date provided", func() {
 			It("should return error response", func() {
-				req := events.APIGatewayProxyRequest{
-					QueryStringParameters: map[string]string{
+				req := handler.Request{
+					QueryParameters: map[string]string{
 						"lat":  "42.0",
 						"lon":  "23.0",
-						"date": "2025-07-28",
+						"date": time.Now().UTC().Add(10 * 24 * time.Hour).Format("2006-01-02"),
 					},
 				}
-				resp, err := ws.HandleRequest(context.TODO(), req)
-				Expect(err).ToNot(HaveOccurred())
+				resp := ws.Handle(context.TODO(), req)
 				Expect(resp.StatusCode).To(Equal(400))
 				Expect(resp.Body).To(ContainSubstring("Invalid date: Date could not be 7 day from today"))
 			})
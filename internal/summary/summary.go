@@ -0,0 +1,214 @@
+// Package summary turns a day's forecast into a one-line summary such as
+// "Mostly sunny, high of 27°C, low chance of rain, very high UV", using
+// message catalogs that can be registered per language.
+package summary
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+	"weather-service/internal/uv"
+)
+
+// Day holds the values a summary is made of.
+type Day struct {
+	// WeatherCode is the WMO weather code, nil when it is unknown.
+	WeatherCode *int
+	TempMax     float64
+	// TempMin is nil when it is unknown.
+	TempMin *float64
+	// Fahrenheit is set when the temperatures are in °F rather than °C.
+	Fahrenheit bool
+	// RainProbability is nil when it is unknown.
+	RainProbability *float64
+	UVCategory      uv.Category
+}
+
+// Sky is a coarse class of WMO weather codes.
+type Sky string
+
+const (
+	Sunny        Sky = "sunny"
+	MostlySunny  Sky = "mostly sunny"
+	PartlyCloudy Sky = "partly cloudy"
+	Cloudy       Sky = "cloudy"
+	Fog          Sky = "fog"
+	Drizzle      Sky = "drizzle"
+	Rain         Sky = "rain"
+	Snow         Sky = "snow"
+	Thunderstorm Sky = "thunderstorm"
+)
+
+// SkyOf returns the sky of a WMO weather code.
+func SkyOf(code int) (Sky, bool) {
+	switch {
+	case code == 0:
+		return Sunny, true
+	case code == 1:
+		return MostlySunny, true
+	case code == 2:
+		return PartlyCloudy, true
+	case code == 3:
+		return Cloudy, true
+	case code == 45 || code == 48:
+		return Fog, true
+	case code >= 51 && code <= 57:
+		return Drizzle, true
+	case code >= 61 && code <= 67, code >= 80 && code <= 82:
+		return Rain, true
+	case code >= 71 && code <= 77, code == 85 || code == 86:
+		return Snow, true
+	case code >= 95 && code <= 99:
+		return Thunderstorm, true
+	}
+	return "", false
+}
+
+// Chance classes a precipitation probability.
+type Chance string
+
+const (
+	LowChance      Chance = "low"
+	ModerateChance Chance = "moderate"
+	HighChance     Chance = "high"
+)
+
+// ChanceOf returns the class of a precipitation probability in percent.
+func ChanceOf(probability float64) Chance {
+	switch {
+	case probability < 30:
+		return LowChance
+	case probability < 60:
+		return ModerateChance
+	default:
+		return HighChance
+	}
+}
+
+// Catalog holds the messages of one language. High and Low are fmt formats
+// of the formatted temperature. UV categories without a message, usually
+// low, are left out of summaries.
+type Catalog struct {
+	Sky  map[Sky]string
+	High string
+	Low  string
+	Rain map[Chance]string
+	UV   map[uv.Category]string
+}
+
+// Summarise returns the summary of d, its clauses separated by commas.
+func (c Catalog) Summarise(d Day) string {
+	var clauses []string
+	if d.WeatherCode != nil {
+		if sky, ok := SkyOf(*d.WeatherCode); ok {
+			clauses = append(clauses, c.Sky[sky])
+		}
+	}
+	clauses = append(clauses, fmt.Sprintf(c.High, temperature(d.TempMax, d.Fahrenheit)))
+	if d.TempMin != nil {
+		clauses = append(clauses, fmt.Sprintf(c.Low, temperature(*d.TempMin, d.Fahrenheit)))
+	}
+	if d.RainProbability != nil {
+		clauses = append(clauses, c.Rain[ChanceOf(*d.RainProbability)])
+	}
+	if message, ok := c.UV[d.UVCategory]; ok {
+		clauses = append(clauses, message)
+	}
+	return capitalise(strings.Join(clauses, ", "))
+}
+
+func temperature(value float64, fahrenheit bool) string {
+	unit := "C"
+	if fahrenheit {
+		unit = "F"
+	}
+	return fmt.Sprintf("%d°%s", int(math.Round(value)), unit)
+}
+
+func capitalise(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// DefaultLanguage is the language of summaries when none is requested.
+const DefaultLanguage = "en"
+
+var catalogs = map[string]Catalog{
+	"en": {
+		Sky: map[Sky]string{
+			Sunny:        "sunny",
+			MostlySunny:  "mostly sunny",
+			PartlyCloudy: "partly cloudy",
+			Cloudy:       "cloudy",
+			Fog:          "foggy",
+			Drizzle:      "drizzle",
+			Rain:         "rain",
+			Snow:         "snow",
+			Thunderstorm: "thunderstorms",
+		},
+		High: "high of %s",
+		Low:  "low of %s",
+		Rain: map[Chance]string{
+			LowChance:      "low chance of rain",
+			ModerateChance: "moderate chance of rain",
+			HighChance:     "high chance of rain",
+		},
+		UV: map[uv.Category]string{
+			uv.Moderate: "moderate UV",
+			uv.High:     "high UV",
+			uv.VeryHigh: "very high UV",
+			uv.Extreme:  "extreme UV",
+		},
+	},
+	"bg": {
+		Sky: map[Sky]string{
+			Sunny:        "слънчево",
+			MostlySunny:  "предимно слънчево",
+			PartlyCloudy: "разкъсана облачност",
+			Cloudy:       "облачно",
+			Fog:          "мъгливо",
+			Drizzle:      "ръмеж",
+			Rain:         "дъжд",
+			Snow:         "сняг",
+			Thunderstorm: "гръмотевични бури",
+		},
+		High: "максимална %s",
+		Low:  "минимална %s",
+		Rain: map[Chance]string{
+			LowChance:      "малка вероятност за валежи",
+			ModerateChance: "умерена вероятност за валежи",
+			HighChance:     "голяма вероятност за валежи",
+		},
+		UV: map[uv.Category]string{
+			uv.Moderate: "умерен UV индекс",
+			uv.High:     "висок UV индекс",
+			uv.VeryHigh: "много висок UV индекс",
+			uv.Extreme:  "екстремен UV индекс",
+		},
+	},
+}
+
+// Register adds or replaces the catalog of a language. It is meant to be
+// called during initialisation.
+func Register(lang string, c Catalog) {
+	catalogs[lang] = c
+}
+
+// Lookup returns the catalog of a language.
+func Lookup(lang string) (Catalog, bool) {
+	c, ok := catalogs[lang]
+	return c, ok
+}
+
+// Languages returns the languages with a catalog, sorted.
+func Languages() []string {
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
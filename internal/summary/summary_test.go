@@ -0,0 +1,65 @@
+package summary_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"weather-service/internal/summary"
+	"weather-service/internal/uv"
+)
+
+var _ = Describe("Summary", func() {
+	code := func(c int) *int { return &c }
+	percent := func(p float64) *float64 { return &p }
+	low := 15.4
+
+	DescribeTable("Summarise",
+		func(lang string, day summary.Day, expected string) {
+			c, ok := summary.Lookup(lang)
+			Expect(ok).To(BeTrue())
+			Expect(c.Summarise(day)).To(Equal(expected))
+		},
+		Entry("english",
+			"en", summary.Day{WeatherCode: code(1), TempMax: 26.7, RainProbability: percent(10), UVCategory: uv.VeryHigh},
+			"Mostly sunny, high of 27°C, low chance of rain, very high UV"),
+		Entry("with a low, in fahrenheit",
+			"en", summary.Day{WeatherCode: code(63), TempMax: 71.6, TempMin: &low, Fahrenheit: true, RainProbability: percent(80), UVCategory: uv.Low},
+			"Rain, high of 72°F, low of 15°F, high chance of rain"),
+		Entry("without a weather code",
+			"en", summary.Day{TempMax: -2.4, RainProbability: percent(45), UVCategory: uv.Moderate},
+			"High of -2°C, moderate chance of rain, moderate UV"),
+		Entry("bulgarian",
+			"bg", summary.Day{WeatherCode: code(95), TempMax: 30, TempMin: &low, RainProbability: percent(70), UVCategory: uv.High},
+			"Гръмотевични бури, максимална 30°C, минимална 15°C, голяма вероятност за валежи, висок UV индекс"),
+		Entry("without a rain probability or UV, as for past days",
+			"en", summary.Day{WeatherCode: code(3), TempMax: 12, TempMin: &low},
+			"Cloudy, high of 12°C, low of 15°C"),
+	)
+
+	DescribeTable("SkyOf",
+		func(c int, expected summary.Sky) {
+			sky, ok := summary.SkyOf(c)
+			Expect(ok).To(BeTrue())
+			Expect(sky).To(Equal(expected))
+		},
+		Entry("clear", 0, summary.Sunny),
+		Entry("fog", 48, summary.Fog),
+		Entry("freezing drizzle", 57, summary.Drizzle),
+		Entry("rain showers", 81, summary.Rain),
+		Entry("snow showers", 86, summary.Snow),
+		Entry("hail", 99, summary.Thunderstorm),
+	)
+
+	It("should not know codes outside WMO 4677", func() {
+		_, ok := summary.SkyOf(42)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should use registered catalogs", func() {
+		en, _ := summary.Lookup("en")
+		summary.Register("en-shout", summary.Catalog{Sky: en.Sky, High: "HIGH %s", Low: en.Low, Rain: en.Rain})
+		c, ok := summary.Lookup("en-shout")
+		Expect(ok).To(BeTrue())
+		Expect(c.Summarise(summary.Day{TempMax: 20, RainProbability: percent(10), UVCategory: uv.Extreme})).To(Equal("HIGH 20°C, low chance of rain"))
+		Expect(summary.Languages()).To(ContainElements("bg", "en", "en-shout"))
+	})
+})
@@ -0,0 +1,121 @@
+// Package uv interprets the UV index: WHO risk categories, sun-protection
+// guidance and safe exposure times per Fitzpatrick skin type.
+package uv
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Category is a WHO UV risk category.
+type Category string
+
+const (
+	Low      Category = "low"
+	Moderate Category = "moderate"
+	High     Category = "high"
+	VeryHigh Category = "very high"
+	Extreme  Category = "extreme"
+)
+
+// CategoryOf returns the category of a UV index, which WHO reports rounded
+// to a whole number.
+func CategoryOf(index float64) Category {
+	switch rounded := math.Round(index); {
+	case rounded <= 2:
+		return Low
+	case rounded <= 5:
+		return Moderate
+	case rounded <= 7:
+		return High
+	case rounded <= 10:
+		return VeryHigh
+	default:
+		return Extreme
+	}
+}
+
+var protection = map[Category][]string{
+	Low: {
+		"No protection needed, you can safely stay outside",
+		"Wear sunglasses on bright days",
+	},
+	Moderate: {
+		"Seek shade during midday hours",
+		"Wear a shirt, a hat and sunglasses",
+		"Apply SPF 30+ sunscreen",
+	},
+	High: {
+		"Seek shade during midday hours",
+		"Wear a shirt, a hat and sunglasses",
+		"Apply SPF 30+ sunscreen every 2 hours",
+	},
+	VeryHigh: {
+		"Avoid being outside between 11:00 and 16:00",
+		"Seek shade",
+		"A shirt, a hat, sunglasses and SPF 50+ sunscreen are a must",
+	},
+	Extreme: {
+		"Avoid being outside between 11:00 and 16:00",
+		"Seek shade",
+		"A shirt, a hat, sunglasses and SPF 50+ sunscreen are a must",
+		"Unprotected skin burns in minutes",
+	},
+}
+
+// Protection returns the recommended protection measures.
+func (c Category) Protection() []string {
+	return protection[c]
+}
+
+// SkinType is a Fitzpatrick skin type, from I (always burns) to VI (never burns).
+type SkinType int
+
+// SkinTypes lists every skin type.
+var SkinTypes = []SkinType{1, 2, 3, 4, 5, 6}
+
+var romanNumerals = []string{"I", "II", "III", "IV", "V", "VI"}
+
+// minimalErythemalDose is the erythemally weighted dose, in J/m², that
+// reddens the skin of each skin type.
+var minimalErythemalDose = map[SkinType]float64{1: 200, 2: 250, 3: 350, 4: 450, 5: 600, 6: 1000}
+
+// irradiancePerIndex is the erythemally weighted irradiance, in W/m², of one
+// UV index unit.
+const irradiancePerIndex = 0.025
+
+// ParseSkinType parses a skin type given as 1-6 or I-VI.
+func ParseSkinType(s string) (SkinType, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	for i, numeral := range romanNumerals {
+		if s == numeral {
+			return SkinType(i + 1), nil
+		}
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 1 && n <= len(romanNumerals) {
+		return SkinType(n), nil
+	}
+	return 0, fmt.Errorf("Invalid skinType: %s, expected 1-6 or I-VI", s)
+}
+
+// String returns the roman numeral of the skin type.
+func (t SkinType) String() string {
+	if t < 1 || int(t) > len(romanNumerals) {
+		return strconv.Itoa(int(t))
+	}
+	return romanNumerals[t-1]
+}
+
+// SafeExposure estimates how long skin of type t can be exposed at the given
+// UV index before it reddens. ok is false when the index is too low to burn.
+func SafeExposure(index float64, t SkinType) (d time.Duration, ok bool) {
+	dose, known := minimalErythemalDose[t]
+	if !known || index <= 0 {
+		return 0, false
+	}
+	seconds := dose / (index * irradiancePerIndex)
+	return time.Duration(math.Round(seconds)) * time.Second, true
+}
@@ -0,0 +1,13 @@
+package uv_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestUV(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "UV Suite")
+}
@@ -0,0 +1,62 @@
+package uv_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"time"
+	"weather-service/internal/uv"
+)
+
+var _ = Describe("UV", func() {
+	DescribeTable("CategoryOf",
+		func(index float64, expected uv.Category) {
+			Expect(uv.CategoryOf(index)).To(Equal(expected))
+		},
+		Entry("zero", 0.0, uv.Low),
+		Entry("2.4 rounds to low", 2.4, uv.Low),
+		Entry("2.5 rounds to moderate", 2.5, uv.Moderate),
+		Entry("5", 5.0, uv.Moderate),
+		Entry("7.05", 7.05, uv.High),
+		Entry("8", 8.0, uv.VeryHigh),
+		Entry("10.4", 10.4, uv.VeryHigh),
+		Entry("11", 11.0, uv.Extreme),
+	)
+
+	It("should recommend protection for every category", func() {
+		for _, c := range []uv.Category{uv.Low, uv.Moderate, uv.High, uv.VeryHigh, uv.Extreme} {
+			Expect(c.Protection()).ToNot(BeEmpty())
+		}
+	})
+
+	DescribeTable("ParseSkinType",
+		func(value string, expected uv.SkinType) {
+			t, err := uv.ParseSkinType(value)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(t).To(Equal(expected))
+		},
+		Entry("number", "2", uv.SkinType(2)),
+		Entry("roman numeral", "iv", uv.SkinType(4)),
+	)
+
+	It("should reject unknown skin types", func() {
+		_, err := uv.ParseSkinType("7")
+		Expect(err).To(MatchError("Invalid skinType: 7, expected 1-6 or I-VI"))
+	})
+
+	It("should estimate shorter safe exposure for fairer skin and higher indexes", func() {
+		fair, ok := uv.SafeExposure(8, 1)
+		Expect(ok).To(BeTrue())
+		Expect(fair).To(Equal(1000 * time.Second))
+
+		dark, _ := uv.SafeExposure(8, 6)
+		Expect(dark).To(BeNumerically(">", fair))
+
+		lower, _ := uv.SafeExposure(4, 1)
+		Expect(lower).To(Equal(2 * fair))
+	})
+
+	It("should not estimate safe exposure without UV", func() {
+		_, ok := uv.SafeExposure(0, 1)
+		Expect(ok).To(BeFalse())
+	})
+})
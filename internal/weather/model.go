@@ -1,14 +1,150 @@
 package weather
 
+import (
+	"encoding/json"
+	"weather-service/internal/handler"
+)
+
 type Daily struct {
-	Time                        []string  `json:"time"`
-	Temperature2mMax            []float64 `json:"temperature_2m_max"`
-	UVIndexMax                  []float64 `json:"uv_index_max"`
-	PrecipitationProbabilityMax []float64 `json:"precipitation_probability_max"`
+	Time []string `json:"time"`
+	// Temperature2mMax is null for days at the end of a long horizon the
+	// provider has no forecast for.
+	Temperature2mMax            []*float64 `json:"temperature_2m_max"`
+	UVIndexMax                  []*float64 `json:"uv_index_max"`
+	PrecipitationProbabilityMax []*float64 `json:"precipitation_probability_max"`
+	WeatherCode                 []*int     `json:"weather_code"`
+	Sunrise                     []string   `json:"sunrise"`
+	Sunset                      []string   `json:"sunset"`
+	DaylightDuration            []*float64 `json:"daylight_duration"`
+	PrecipitationSum            []*float64 `json:"precipitation_sum"`
+	Temperature2mMin            []*float64 `json:"temperature_2m_min"`
+	ApparentTemperatureMax      []*float64 `json:"apparent_temperature_max"`
+	ApparentTemperatureMin      []*float64 `json:"apparent_temperature_min"`
+	RainSum                     []*float64 `json:"rain_sum"`
+	SnowfallSum                 []*float64 `json:"snowfall_sum"`
+	PrecipitationHours          []*float64 `json:"precipitation_hours"`
+	WindSpeed10mMax             []*float64 `json:"wind_speed_10m_max"`
+	WindGusts10mMax             []*float64 `json:"wind_gusts_10m_max"`
+	WindDirection10mDominant    []*float64 `json:"wind_direction_10m_dominant"`
+	// Variables holds the other registered variables by their Open-Meteo name.
+	Variables map[string][]interface{} `json:"-"`
+}
+
+func (d *Daily) UnmarshalJSON(data []byte) error {
+	type plainDaily Daily
+	if err := json.Unmarshal(data, (*plainDaily)(d)); err != nil {
+		return err
+	}
+	variables, err := decodeVariables(data, handler.DefaultVariables)
+	d.Variables = variables
+	return err
 }
 
 type OpenMeteoResponse struct {
 	Daily     Daily   `json:"daily"`
 	Latitude  float64 `json:"latitude"`
 	Longitude float64 `json:"longitude"`
+	// Timezone is the IANA timezone Open-Meteo resolved for timezone=auto.
+	Timezone string `json:"timezone"`
+}
+
+// ArchiveDaily holds the archive's daily values. They are pointers because
+// the archive returns null for days it has no data for yet.
+type ArchiveDaily struct {
+	Time                        []string   `json:"time"`
+	Temperature2mMax            []*float64 `json:"temperature_2m_max"`
+	UVIndexMax                  []*float64 `json:"uv_index_max"`
+	PrecipitationProbabilityMax []*float64 `json:"precipitation_probability_max"`
+	PrecipitationSum            []*float64 `json:"precipitation_sum"`
+	WeatherCode                 []*int     `json:"weather_code"`
+	Sunrise                     []string   `json:"sunrise"`
+	Sunset                      []string   `json:"sunset"`
+	DaylightDuration            []*float64 `json:"daylight_duration"`
+	Temperature2mMin            []*float64 `json:"temperature_2m_min"`
+	ApparentTemperatureMax      []*float64 `json:"apparent_temperature_max"`
+	ApparentTemperatureMin      []*float64 `json:"apparent_temperature_min"`
+	RainSum                     []*float64 `json:"rain_sum"`
+	SnowfallSum                 []*float64 `json:"snowfall_sum"`
+	PrecipitationHours          []*float64 `json:"precipitation_hours"`
+	WindSpeed10mMax             []*float64 `json:"wind_speed_10m_max"`
+	WindGusts10mMax             []*float64 `json:"wind_gusts_10m_max"`
+	WindDirection10mDominant    []*float64 `json:"wind_direction_10m_dominant"`
+	// Variables holds the registered variables other than
+	// handler.DefaultVariables by their Open-Meteo name.
+	Variables map[string][]interface{} `json:"-"`
+}
+
+func (d *ArchiveDaily) UnmarshalJSON(data []byte) error {
+	type plainArchiveDaily ArchiveDaily
+	if err := json.Unmarshal(data, (*plainArchiveDaily)(d)); err != nil {
+		return err
+	}
+	variables, err := decodeVariables(data, handler.DefaultVariables)
+	d.Variables = variables
+	return err
+}
+
+// decodeVariables decodes the columns of a daily object that are registered
+// variables, except the skipped ones. Values are float64, string or nil.
+func decodeVariables(data []byte, skip []string) (map[string][]interface{}, error) {
+	var columns map[string][]interface{}
+	if err := json.Unmarshal(data, &columns); err != nil {
+		return nil, err
+	}
+
+	variables := make(map[string][]interface{})
+	for name, values := range columns {
+		if v, ok := handler.LookupVariable(name); !ok || v.Name != name || contains(skip, name) {
+			continue
+		}
+		variables[name] = values
+	}
+	return variables, nil
+}
+
+// variablesAt returns the non-null values of day i, or nil when there are none.
+func variablesAt(variables map[string][]interface{}, i int) map[string]interface{} {
+	var fields map[string]interface{}
+	for name, values := range variables {
+		if i >= len(values) || values[i] == nil {
+			continue
+		}
+		if fields == nil {
+			fields = make(map[string]interface{})
+		}
+		fields[name] = values[i]
+	}
+	return fields
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+type OpenMeteoArchiveResponse struct {
+	Daily     ArchiveDaily `json:"daily"`
+	Latitude  float64      `json:"latitude"`
+	Longitude float64      `json:"longitude"`
+	Timezone  string       `json:"timezone"`
+}
+
+type Hourly struct {
+	Time                     []string  `json:"time"`
+	Temperature2m            []float64 `json:"temperature_2m"`
+	PrecipitationProbability []float64 `json:"precipitation_probability"`
+	Precipitation            []float64 `json:"precipitation"`
+	WindSpeed10m             []float64 `json:"wind_speed_10m"`
+	CloudCover               []float64 `json:"cloud_cover"`
+}
+
+type OpenMeteoHourlyResponse struct {
+	Hourly    Hourly  `json:"hourly"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Timezone  string  `json:"timezone"`
 }
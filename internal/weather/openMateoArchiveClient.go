@@ -0,0 +1,104 @@
+package weather
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"weather-service/internal/handler"
+)
+
+// ArchiveLagDays is about how many days the archive runs behind today. The
+// forecast has the days it does not have yet, with past_days.
+const ArchiveLagDays = 5
+
+type OpenMateoArchiveClient struct {
+	HttpClient HttpRequester
+	Url        string //"https://archive-api.open-meteo.com/v1/archive?latitude=%s&longitude=%s&start_date=%s&end_date=%s&timezone=auto", daily= is added per call
+}
+
+func NewOpenMateoArchiveClient(hc HttpRequester, url string) *OpenMateoArchiveClient {
+	return &OpenMateoArchiveClient{
+		HttpClient: hc,
+		Url:        url,
+	}
+}
+
+// GetArchive returns the observed daily values from start to end, inclusive,
+// of handler.DefaultArchiveVariables and the given extra fields. Days the
+// archive has no temperature for yet are left out.
+func (c *OpenMateoArchiveClient) GetArchive(lat, long, start, end string, fields ...string) (handler.ForecastMap, error) {
+	logrus.WithFields(logrus.Fields{
+		"lat":    lat,
+		"long":   long,
+		"start":  start,
+		"end":    end,
+		"fields": fields,
+	}).Info("Going to get archive from OpenMateo")
+
+	daily := handler.FetchedVariables(handler.MergeVariables(handler.DefaultArchiveVariables, fields...))
+	url := fmt.Sprintf(withoutParam(c.Url, "daily"), lat, long, start, end) + "&daily=" + strings.Join(daily, ",")
+
+	var oar OpenMeteoArchiveResponse
+	if err := getJSON(c.HttpClient, url, &oar); err != nil {
+		return nil, err
+	}
+
+	fm := make(handler.ForecastMap)
+	for i, date := range oar.Daily.Time {
+		temp := valueAt(oar.Daily.Temperature2mMax, i)
+		if temp == nil {
+			continue
+		}
+
+		forecast := handler.Forecast{
+			Latitude:   fmt.Sprintf("%.4f", oar.Latitude),
+			Longitude:  fmt.Sprintf("%.4f", oar.Longitude),
+			Timezone:   oar.Timezone,
+			Temp2max:   *temp,
+			UvIndexMax: valueAt(oar.Daily.UVIndexMax, i),
+			// nil, the archive has no probabilities, PrecipSum is what fell
+			PrecipProbability: valueAt(oar.Daily.PrecipitationProbabilityMax, i),
+			Temp2min:          valueAt(oar.Daily.Temperature2mMin, i),
+			ApparentTempMax:   valueAt(oar.Daily.ApparentTemperatureMax, i),
+			ApparentTempMin:   valueAt(oar.Daily.ApparentTemperatureMin, i),
+			PrecipSum:         valueAt(oar.Daily.PrecipitationSum, i),
+			RainSum:           valueAt(oar.Daily.RainSum, i),
+			SnowfallSum:       valueAt(oar.Daily.SnowfallSum, i),
+			PrecipHours:       valueAt(oar.Daily.PrecipitationHours, i),
+			WindSpeedMax:      valueAt(oar.Daily.WindSpeed10mMax, i),
+			WindGustsMax:      valueAt(oar.Daily.WindGusts10mMax, i),
+			WindDirection:     valueAt(oar.Daily.WindDirection10mDominant, i),
+			Sunrise:           stringAt(oar.Daily.Sunrise, i),
+			Sunset:            stringAt(oar.Daily.Sunset, i),
+			DaylightDuration:  valueAt(oar.Daily.DaylightDuration, i),
+			WeatherCode:       codeAt(oar.Daily.WeatherCode, i),
+			Fields:            variablesAt(oar.Daily.Variables, i),
+		}
+		fm[date] = forecast
+	}
+	return fm, nil
+}
+
+// codeAt returns codes[i], or nil when the provider has no code for the day.
+func codeAt(codes []*int, i int) *int {
+	if i >= len(codes) {
+		return nil
+	}
+	return codes[i]
+}
+
+// stringAt returns values[i], or "" when the variable was not returned.
+func stringAt(values []string, i int) string {
+	if i >= len(values) {
+		return ""
+	}
+	return values[i]
+}
+
+// valueAt returns values[i], or nil when the variable was not requested.
+func valueAt(values []*float64, i int) *float64 {
+	if i >= len(values) {
+		return nil
+	}
+	return values[i]
+}
@@ -0,0 +1,110 @@
+package weather_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"io"
+	"net/http"
+	"weather-service/helper/mockutil"
+	"weather-service/internal/weather"
+	"weather-service/internal/weather/mocks"
+)
+
+var _ = Describe("OpenMateoArchiveClient", mockutil.Mockable(func(helper *mockutil.Helper) {
+
+	var (
+		mockHTTPClient *mocks.MockHttpRequester
+		oac            *weather.OpenMateoArchiveClient
+	)
+
+	BeforeEach(func() {
+		mockHTTPClient = mocks.NewMockHttpRequester(helper.Controller())
+		oac = weather.NewOpenMateoArchiveClient(mockHTTPClient, "testurl.com/archive?latitude=%s&longitude=%s&start_date=%s&end_date=%s")
+	})
+
+	Context("GetArchive", func() {
+		When("everything works", func() {
+			var requestedURL string
+			BeforeEach(func() {
+				response := "{\"latitude\":43.0,\"longitude\":23.0,\"daily\":{\"time\":[\"2020-07-10\",\"2020-07-11\",\"2020-07-12\"],\"temperature_2m_max\":[20.8,25.1,null],\"precipitation_sum\":[0.0,4.2,null]}}"
+				mockHTTPClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					requestedURL = req.URL.String()
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(bytes.NewBufferString(response)),
+					}, nil
+				}).Times(1)
+			})
+
+			It("should return the days the archive has data for", func() {
+				resp, err := oac.GetArchive("43.0", "23.0", "2020-07-10", "2020-07-12")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(requestedURL).To(Equal("testurl.com/archive?latitude=43.0&longitude=23.0&start_date=2020-07-10&end_date=2020-07-12&daily=temperature_2m_max,precipitation_sum,weather_code,sunrise,sunset,daylight_duration,temperature_2m_min,apparent_temperature_max,apparent_temperature_min,rain_sum,snowfall_sum,precipitation_hours,wind_speed_10m_max,wind_gusts_10m_max,wind_direction_10m_dominant"))
+				Expect(resp).To(HaveLen(2))
+				Expect(resp["2020-07-10"].Latitude).To(Equal("43.0000"))
+				Expect(resp["2020-07-10"].Temp2max).To(Equal(20.8))
+				Expect(resp["2020-07-10"].PrecipProbability).To(BeNil())
+				Expect(resp["2020-07-11"].Temp2max).To(Equal(25.1))
+				Expect(resp["2020-07-11"].PrecipProbability).To(BeNil())
+				Expect(resp["2020-07-11"].UvIndexMax).To(BeNil())
+				Expect(resp).ToNot(HaveKey("2020-07-12"))
+				Expect(*resp["2020-07-11"].PrecipSum).To(Equal(4.2))
+				Expect(resp["2020-07-11"].Fields).To(BeNil())
+			})
+		})
+
+		When("extra fields are requested", func() {
+			var requestedURL string
+			BeforeEach(func() {
+				response := "{\"latitude\":43.0,\"longitude\":23.0,\"daily\":{\"time\":[\"2020-07-10\"],\"temperature_2m_max\":[20.8],\"precipitation_sum\":[0.0],\"temperature_2m_min\":[12.4],\"sunshine_duration\":[18.7]}}"
+				mockHTTPClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					requestedURL = req.URL.String()
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(bytes.NewBufferString(response)),
+					}, nil
+				}).Times(1)
+			})
+
+			It("should ask for them and return them in Fields", func() {
+				resp, err := oac.GetArchive("43.0", "23.0", "2020-07-10", "2020-07-10", "sunshine_duration")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(requestedURL).To(HaveSuffix("&daily=temperature_2m_max,precipitation_sum,weather_code,sunrise,sunset,daylight_duration,temperature_2m_min,apparent_temperature_max,apparent_temperature_min,rain_sum,snowfall_sum,precipitation_hours,wind_speed_10m_max,wind_gusts_10m_max,wind_direction_10m_dominant,sunshine_duration"))
+				Expect(resp["2020-07-10"].Fields).To(Equal(map[string]interface{}{"sunshine_duration": 18.7}))
+				Expect(*resp["2020-07-10"].Temp2min).To(Equal(12.4))
+			})
+		})
+
+		When("uv index and precipitation probability are available", func() {
+			BeforeEach(func() {
+				response := "{\"latitude\":43.0,\"longitude\":23.0,\"daily\":{\"time\":[\"2020-07-10\"],\"temperature_2m_max\":[20.8],\"uv_index_max\":[5.3],\"precipitation_probability_max\":[30],\"precipitation_sum\":[4.2]}}"
+				mockHTTPClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewBufferString(response)),
+				}, nil).Times(1)
+			})
+
+			It("should use them", func() {
+				resp, err := oac.GetArchive("43.0", "23.0", "2020-07-10", "2020-07-10")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(*resp["2020-07-10"].UvIndexMax).To(Equal(5.3))
+				Expect(*resp["2020-07-10"].PrecipProbability).To(Equal(float64(30)))
+			})
+		})
+
+		When("request fails", func() {
+			BeforeEach(func() {
+				mockHTTPClient.EXPECT().Do(gomock.Any()).Return(&http.Response{}, errors.New("error")).Times(1)
+			})
+
+			It("should return error", func() {
+				resp, err := oac.GetArchive("43.0", "23.0", "2020-07-10", "2020-07-10")
+				Expect(err).To(HaveOccurred())
+				Expect(resp).To(BeNil())
+			})
+		})
+	})
+}))
@@ -1,60 +1,272 @@
 package weather
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"net/http"
+	"strings"
 	"weather-service/internal/handler"
 	"weather-service/internal/logging"
 )
 
 //go:generate mockgen --source=openMateoClient.go --destination mocks/openMateoClient.go --package mocks
 
+// maxLocationsPerRequest bounds how many locations are sent in a single
+// Open-Meteo call, to keep the URL at a reasonable length.
+const maxLocationsPerRequest = 50
+
+// MaxForecastDays is the longest forecast_days Open-Meteo accepts.
+const MaxForecastDays = 16
+
+// hourlyVariables are the hourly variables GetHourlyForecast asks for.
+var hourlyVariables = []string{"temperature_2m", "precipitation_probability", "precipitation", "wind_speed_10m", "cloud_cover"}
+
 type HttpRequester interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
 type OpenMateoClient struct {
 	HttpClient HttpRequester
-	Url        string //"https://api.open-meteo.com/v1/forecast?latitude=%s&longitude=%s&daily=temperature_2m_max,uv_index_max,precipitation_probability_max&timezone=auto"
+	Url        string //"https://api.open-meteo.com/v1/forecast?latitude=%s&longitude=%s&timezone=auto", daily= is added per call
+	HourlyUrl  string //"https://api.open-meteo.com/v1/forecast?latitude=%s&longitude=%s&timezone=auto", hourly= is added per call
+	// ForecastDays is sent as forecast_days when set, otherwise Open-Meteo's default of 7 days applies.
+	ForecastDays int
+	// PastDays is sent as past_days with daily forecasts when set, for the
+	// days before today the archive does not have yet.
+	PastDays int
 }
 
-func NewOpenMateoClient(hc HttpRequester, url string) *OpenMateoClient {
+func NewOpenMateoClient(hc HttpRequester, url, hourlyUrl string) *OpenMateoClient {
 	return &OpenMateoClient{
 		HttpClient: hc,
 		Url:        url,
+		HourlyUrl:  hourlyUrl,
 	}
 }
 
-func (c *OpenMateoClient) GetForecast(lat, long string) (handler.ForecastMap, error) {
+// GetForecast fetches handler.DefaultVariables and the given extra fields.
+func (c *OpenMateoClient) GetForecast(lat, long string, fields ...string) (handler.ForecastMap, error) {
+	logrus.WithFields(logrus.Fields{
+		"lat":    lat,
+		"long":   long,
+		"fields": fields,
+	}).Info("Going to get forecast from OpenMateo")
+
+	var opr OpenMeteoResponse
+	if err := c.get(c.forecastURL(c.Url, lat, long, "daily", dailyVariables(fields)), &opr); err != nil {
+		return nil, err
+	}
+	return toForecastMap(opr), nil
+}
+
+// GetForecasts returns one ForecastMap per location, in the same order as
+// locations. Locations are sent to Open-Meteo as comma-separated lists, in
+// chunks of maxLocationsPerRequest.
+func (c *OpenMateoClient) GetForecasts(locations []handler.Location, fields ...string) ([]handler.ForecastMap, error) {
+	logrus.WithFields(logrus.Fields{
+		"locations": len(locations),
+	}).Info("Going to get forecasts for multiple locations from OpenMateo")
+
+	fms := make([]handler.ForecastMap, 0, len(locations))
+	for start := 0; start < len(locations); start += maxLocationsPerRequest {
+		chunk := locations[start:min(start+maxLocationsPerRequest, len(locations))]
+
+		lats := make([]string, len(chunk))
+		longs := make([]string, len(chunk))
+		for i, location := range chunk {
+			lats[i] = location.Lat
+			longs[i] = location.Lon
+		}
+
+		var raw json.RawMessage
+		if err := c.get(c.forecastURL(c.Url, strings.Join(lats, ","), strings.Join(longs, ","), "daily", dailyVariables(fields)), &raw); err != nil {
+			return nil, err
+		}
+
+		oprs, err := decodeOpenMeteoResponses(raw)
+		if err != nil {
+			logging.LogError(err, map[string]interface{}{"locations": len(chunk)})
+			return nil, err
+		}
+		if len(oprs) != len(chunk) {
+			return nil, fmt.Errorf("expected %d forecasts from OpenMateo, got %d", len(chunk), len(oprs))
+		}
+
+		for _, opr := range oprs {
+			fms = append(fms, toForecastMap(opr))
+		}
+	}
+	return fms, nil
+}
+
+func (c *OpenMateoClient) GetHourlyForecast(lat, long string) (handler.HourlyForecastMap, error) {
 	logrus.WithFields(logrus.Fields{
 		"lat":  lat,
 		"long": long,
-	}).Info("Going to get forecast from OpenMateo")
+	}).Info("Going to get hourly forecast from OpenMateo")
 
-	url := fmt.Sprintf(c.Url, lat, long)
-	req, _ := http.NewRequest("GET", url, nil)
-	resp, err := c.HttpClient.Do(req)
-	if err != nil {
+	var opr OpenMeteoHourlyResponse
+	if err := c.get(c.forecastURL(c.HourlyUrl, lat, long, "hourly", hourlyVariables), &opr); err != nil {
+		return nil, err
+	}
+	if err := checkHourly(opr.Hourly); err != nil {
+		logging.LogError(err, map[string]interface{}{"lat": lat, "long": long})
 		return nil, err
 	}
-	defer resp.Body.Close()
+
+	fm := make(handler.HourlyForecastMap)
+	for i := 0; i < len(opr.Hourly.Time); i++ {
+		// hourly time is formatted as 2006-01-02T15:04
+		date := opr.Hourly.Time[i]
+		if len(date) > 10 {
+			date = date[:10]
+		}
+		fm[date] = append(fm[date], handler.HourlyForecast{
+			Latitude:          fmt.Sprintf("%.4f", opr.Latitude),
+			Longitude:         fmt.Sprintf("%.4f", opr.Longitude),
+			Timezone:          opr.Timezone,
+			Time:              opr.Hourly.Time[i],
+			Temp2m:            opr.Hourly.Temperature2m[i],
+			PrecipProbability: opr.Hourly.PrecipitationProbability[i],
+			Precipitation:     opr.Hourly.Precipitation[i],
+			WindSpeed10m:      opr.Hourly.WindSpeed10m[i],
+			CloudCover:        opr.Hourly.CloudCover[i],
+		})
+	}
+	return fm, nil
+}
+
+// checkHourly returns an error unless every hourly variable has a value for
+// each time, which they lack when they were not asked for.
+func checkHourly(hourly Hourly) error {
+	for _, variable := range []struct {
+		name   string
+		values []float64
+	}{
+		{"temperature_2m", hourly.Temperature2m},
+		{"precipitation_probability", hourly.PrecipitationProbability},
+		{"precipitation", hourly.Precipitation},
+		{"wind_speed_10m", hourly.WindSpeed10m},
+		{"cloud_cover", hourly.CloudCover},
+	} {
+		if len(variable.values) != len(hourly.Time) {
+			return fmt.Errorf("expected %d hourly %s values from OpenMateo, got %d", len(hourly.Time), variable.name, len(variable.values))
+		}
+	}
+	return nil
+}
+
+// decodeOpenMeteoResponses decodes a multi-location response, which Open-Meteo
+// returns as an array, or as a single object when only one location was asked.
+func decodeOpenMeteoResponses(raw json.RawMessage) ([]OpenMeteoResponse, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var oprs []OpenMeteoResponse
+		if err := json.Unmarshal(trimmed, &oprs); err != nil {
+			return nil, err
+		}
+		return oprs, nil
+	}
 
 	var opr OpenMeteoResponse
-	if err := json.NewDecoder(resp.Body).Decode(&opr); err != nil {
-		logging.LogError(err, map[string]interface{}{"lat": lat, "long": long})
+	if err := json.Unmarshal(trimmed, &opr); err != nil {
 		return nil, err
 	}
+	return []OpenMeteoResponse{opr}, nil
+}
+
+// toForecastMap returns the days of opr by date. Days without a maximum
+// temperature are left out, the provider has no forecast for them.
+func toForecastMap(opr OpenMeteoResponse) handler.ForecastMap {
 	fm := make(handler.ForecastMap)
 	for i := 0; i < len(opr.Daily.Time); i++ {
+		temp := valueAt(opr.Daily.Temperature2mMax, i)
+		if temp == nil {
+			continue
+		}
+
 		fm[opr.Daily.Time[i]] = handler.Forecast{
 			Latitude:          fmt.Sprintf("%.4f", opr.Latitude),
 			Longitude:         fmt.Sprintf("%.4f", opr.Longitude),
-			Temp2max:          opr.Daily.Temperature2mMax[i],
-			UvIndexMax:        opr.Daily.UVIndexMax[i],
-			PrecipProbability: opr.Daily.PrecipitationProbabilityMax[i],
+			Timezone:          opr.Timezone,
+			Temp2max:          *temp,
+			UvIndexMax:        valueAt(opr.Daily.UVIndexMax, i),
+			PrecipProbability: valueAt(opr.Daily.PrecipitationProbabilityMax, i),
+			Temp2min:          valueAt(opr.Daily.Temperature2mMin, i),
+			ApparentTempMax:   valueAt(opr.Daily.ApparentTemperatureMax, i),
+			ApparentTempMin:   valueAt(opr.Daily.ApparentTemperatureMin, i),
+			PrecipSum:         valueAt(opr.Daily.PrecipitationSum, i),
+			RainSum:           valueAt(opr.Daily.RainSum, i),
+			SnowfallSum:       valueAt(opr.Daily.SnowfallSum, i),
+			PrecipHours:       valueAt(opr.Daily.PrecipitationHours, i),
+			WindSpeedMax:      valueAt(opr.Daily.WindSpeed10mMax, i),
+			WindGustsMax:      valueAt(opr.Daily.WindGusts10mMax, i),
+			WindDirection:     valueAt(opr.Daily.WindDirection10mDominant, i),
+			Sunrise:           stringAt(opr.Daily.Sunrise, i),
+			Sunset:            stringAt(opr.Daily.Sunset, i),
+			DaylightDuration:  valueAt(opr.Daily.DaylightDuration, i),
+			WeatherCode:       codeAt(opr.Daily.WeatherCode, i),
+			Fields:            variablesAt(opr.Daily.Variables, i),
 		}
 	}
-	return fm, nil
+	return fm
+}
+
+// dailyVariables returns the daily variables to ask for to get fields.
+func dailyVariables(fields []string) []string {
+	return handler.FetchedVariables(handler.MergeVariables(handler.DefaultVariables, fields...))
+}
+
+// forecastURL fills the lat/long placeholders of template and adds the
+// variables, if any, as the daily or hourly parameter, and the configured
+// forecast horizon and past days. A list older templates carry is replaced.
+func (c *OpenMateoClient) forecastURL(template, lat, long, param string, variables []string) string {
+	url := fmt.Sprintf(withoutParam(template, param), lat, long)
+	if len(variables) > 0 {
+		url += "&" + param + "=" + strings.Join(variables, ",")
+	}
+	if c.ForecastDays > 0 {
+		url += fmt.Sprintf("&forecast_days=%d", c.ForecastDays)
+	}
+	// hourly forecasts are only served from today on
+	if c.PastDays > 0 && param == "daily" {
+		url += fmt.Sprintf("&past_days=%d", c.PastDays)
+	}
+	return url
+}
+
+// withoutParam removes the name parameter from url, unless it is the first
+// one, e.g. the daily= list of older templates.
+func withoutParam(url, name string) string {
+	params := strings.Split(url, "&")
+	kept := []string{params[0]}
+	for _, param := range params[1:] {
+		if !strings.HasPrefix(param, name+"=") {
+			kept = append(kept, param)
+		}
+	}
+	return strings.Join(kept, "&")
+}
+
+// get calls url and decodes the JSON body into v.
+func (c *OpenMateoClient) get(url string, v interface{}) error {
+	return getJSON(c.HttpClient, url, v)
+}
+
+// getJSON calls url with hc and decodes the JSON body into v.
+func getJSON(hc HttpRequester, url string, v interface{}) error {
+	req, _ := http.NewRequest("GET", url, nil)
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		logging.LogError(err, map[string]interface{}{"url": url})
+		return err
+	}
+	return nil
 }
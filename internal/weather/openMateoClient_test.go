@@ -8,7 +8,9 @@ import (
 	. "github.com/onsi/gomega"
 	"io"
 	"net/http"
+	"strings"
 	"weather-service/helper/mockutil"
+	"weather-service/internal/handler"
 	"weather-service/internal/weather"
 	"weather-service/internal/weather/mocks"
 )
@@ -22,13 +24,13 @@ var _ = Describe("OpenMateoClient", mockutil.Mockable(func(helper *mockutil.Help
 
 	BeforeEach(func() {
 		mockHTTPClient = mocks.NewMockHttpRequester(helper.Controller())
-		omc = weather.NewOpenMateoClient(mockHTTPClient, "testurl.com/latitude=%s&longitude=%s")
+		omc = weather.NewOpenMateoClient(mockHTTPClient, "testurl.com/latitude=%s&longitude=%s", "testurl.com/hourly?latitude=%s&longitude=%s")
 	})
 
 	Context("Get", func() {
 		When("everything works", func() {
 			BeforeEach(func() {
-				response := "{\"latitude\":43.0,\"longitude\":23.0,\"daily\":{\"time\":[\"2025-07-10\"],\"temperature_2m_max\":[20.8],\"uv_index_max\":[5.3],\"precipitation_probability_max\":[0]}}"
+				response := "{\"latitude\":43.0,\"longitude\":23.0,\"timezone\":\"Europe/Sofia\",\"daily\":{\"time\":[\"2025-07-10\"],\"temperature_2m_max\":[20.8],\"uv_index_max\":[5.3],\"precipitation_probability_max\":[0]}}"
 				mockHTTPClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
 					StatusCode: 200,
 					Body:       io.NopCloser(bytes.NewBufferString(response)),
@@ -41,9 +43,78 @@ var _ = Describe("OpenMateoClient", mockutil.Mockable(func(helper *mockutil.Help
 				Expect(len(resp)).To(Equal(1))
 				Expect(resp["2025-07-10"].Latitude).To(Equal("43.0000"))
 				Expect(resp["2025-07-10"].Longitude).To(Equal("23.0000"))
+				Expect(resp["2025-07-10"].Timezone).To(Equal("Europe/Sofia"))
+				Expect(resp["2025-07-10"].Temp2max).To(Equal(20.8))
+				Expect(*resp["2025-07-10"].UvIndexMax).To(Equal(5.3))
+				Expect(*resp["2025-07-10"].PrecipProbability).To(Equal(float64(0)))
+			})
+		})
+
+		When("the last day has no maximum temperature", func() {
+			BeforeEach(func() {
+				response := "{\"latitude\":43.0,\"longitude\":23.0,\"daily\":{\"time\":[\"2025-07-24\",\"2025-07-25\"],\"temperature_2m_max\":[20.8,null],\"uv_index_max\":[5.3,null],\"precipitation_probability_max\":[0,null]}}"
+				mockHTTPClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewBufferString(response)),
+				}, nil).Times(1)
+			})
+
+			It("should leave it out", func() {
+				resp, err := omc.GetForecast("43.0", "23.0")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(resp).To(HaveKey("2025-07-24"))
+				Expect(resp).ToNot(HaveKey("2025-07-25"))
+			})
+		})
+
+		When("extra fields are requested", func() {
+			var requestedURL string
+			BeforeEach(func() {
+				response := "{\"latitude\":43.0,\"longitude\":23.0,\"daily\":{\"time\":[\"2025-07-10\",\"2025-07-11\"],\"temperature_2m_max\":[20.8,21.0],\"uv_index_max\":[5.3,5.1],\"precipitation_probability_max\":[0,10]," +
+					"\"temperature_2m_min\":[11.2,null],\"wind_speed_10m_max\":[18.0,null],\"wind_direction_10m_dominant\":[95,null],\"sunshine_duration\":[14.4,null],\"sunrise\":[\"2025-07-10T05:52\",\"2025-07-11T05:53\"],\"unknown\":[1,2]}}"
+				mockHTTPClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					requestedURL = req.URL.String()
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(bytes.NewBufferString(response)),
+					}, nil
+				}).Times(1)
+			})
+
+			It("should ask for them and return them in Fields", func() {
+				resp, err := omc.GetForecast("43.0", "23.0", "sunshine_duration", "sunrise", "uv_index_max")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(requestedURL).To(Equal("testurl.com/latitude=43.0&longitude=23.0&daily=temperature_2m_max,uv_index_max,precipitation_probability_max,weather_code,sunrise,sunset,daylight_duration,temperature_2m_min,apparent_temperature_max,apparent_temperature_min,precipitation_sum,rain_sum,snowfall_sum,precipitation_hours,wind_speed_10m_max,wind_gusts_10m_max,wind_direction_10m_dominant,sunshine_duration"))
 				Expect(resp["2025-07-10"].Temp2max).To(Equal(20.8))
-				Expect(resp["2025-07-10"].UvIndexMax).To(Equal(5.3))
-				Expect(resp["2025-07-10"].PrecipProbability).To(Equal(float64(0)))
+				Expect(resp["2025-07-10"].Fields).To(Equal(map[string]interface{}{"sunshine_duration": 14.4}))
+				Expect(*resp["2025-07-10"].Temp2min).To(Equal(11.2))
+				Expect(*resp["2025-07-10"].WindSpeedMax).To(Equal(18.0))
+				Expect(*resp["2025-07-10"].WindDirection).To(Equal(95.0))
+				Expect(resp["2025-07-10"].WindGustsMax).To(BeNil())
+				Expect(resp["2025-07-10"].Sunrise).To(Equal("2025-07-10T05:52"))
+				Expect(resp["2025-07-11"].Fields).To(BeNil())
+				Expect(resp["2025-07-11"].Sunrise).To(Equal("2025-07-11T05:53"))
+				Expect(resp["2025-07-11"].DaylightDuration).To(BeNil())
+				Expect(resp["2025-07-11"].Temp2min).To(BeNil())
+			})
+		})
+
+		When("weather codes are returned", func() {
+			BeforeEach(func() {
+				response := "{\"latitude\":43.0,\"longitude\":23.0,\"daily\":{\"time\":[\"2025-07-10\",\"2025-07-11\",\"2025-07-12\"],\"temperature_2m_max\":[20.8,21.0,22.0],\"uv_index_max\":[5.3,5.1,5.0],\"precipitation_probability_max\":[0,10,20]," +
+					"\"weather_code\":[95,42,null]}}"
+				mockHTTPClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewBufferString(response)),
+				}, nil).Times(1)
+			})
+
+			It("should return them as they are", func() {
+				resp, err := omc.GetForecast("43.0", "23.0")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(*resp["2025-07-10"].WeatherCode).To(Equal(95))
+				Expect(*resp["2025-07-11"].WeatherCode).To(Equal(42))
+				Expect(resp["2025-07-12"].WeatherCode).To(BeNil())
 			})
 		})
 
@@ -60,4 +131,232 @@ var _ = Describe("OpenMateoClient", mockutil.Mockable(func(helper *mockutil.Help
 			})
 		})
 	})
+
+	Context("ForecastDays", func() {
+		When("a forecast horizon is configured", func() {
+			var requestedURLs []string
+			BeforeEach(func() {
+				omc.ForecastDays = 16
+				mockHTTPClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					requestedURLs = append(requestedURLs, req.URL.String())
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(bytes.NewBufferString("{}")),
+					}, nil
+				}).Times(3)
+			})
+
+			It("should pass it to every forecast call", func() {
+				_, err := omc.GetForecast("43.0", "23.0")
+				Expect(err).ToNot(HaveOccurred())
+				_, err = omc.GetForecasts([]handler.Location{{Lat: "43.0", Lon: "23.0"}})
+				Expect(err).ToNot(HaveOccurred())
+				_, err = omc.GetHourlyForecast("43.0", "23.0")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(requestedURLs).To(Equal([]string{
+					"testurl.com/latitude=43.0&longitude=23.0&daily=temperature_2m_max,uv_index_max,precipitation_probability_max,weather_code,sunrise,sunset,daylight_duration,temperature_2m_min,apparent_temperature_max,apparent_temperature_min,precipitation_sum,rain_sum,snowfall_sum,precipitation_hours,wind_speed_10m_max,wind_gusts_10m_max,wind_direction_10m_dominant&forecast_days=16",
+					"testurl.com/latitude=43.0&longitude=23.0&daily=temperature_2m_max,uv_index_max,precipitation_probability_max,weather_code,sunrise,sunset,daylight_duration,temperature_2m_min,apparent_temperature_max,apparent_temperature_min,precipitation_sum,rain_sum,snowfall_sum,precipitation_hours,wind_speed_10m_max,wind_gusts_10m_max,wind_direction_10m_dominant&forecast_days=16",
+					"testurl.com/hourly?latitude=43.0&longitude=23.0&hourly=temperature_2m,precipitation_probability,precipitation,wind_speed_10m,cloud_cover&forecast_days=16",
+				}))
+			})
+		})
+	})
+
+	Context("older templates", func() {
+		When("they list the variables themselves", func() {
+			var requestedURLs []string
+			BeforeEach(func() {
+				omc = weather.NewOpenMateoClient(mockHTTPClient,
+					"testurl.com/forecast?latitude=%s&longitude=%s&daily=temperature_2m_max,uv_index_max,precipitation_probability_max&timezone=auto",
+					"testurl.com/forecast?latitude=%s&longitude=%s&hourly=temperature_2m&timezone=auto")
+				mockHTTPClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					requestedURLs = append(requestedURLs, req.URL.String())
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(bytes.NewBufferString("{}")),
+					}, nil
+				}).Times(2)
+			})
+
+			It("should replace the list instead of adding a second one", func() {
+				_, err := omc.GetForecast("43.0", "23.0", "sunshine_duration")
+				Expect(err).ToNot(HaveOccurred())
+				_, err = omc.GetHourlyForecast("43.0", "23.0")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(requestedURLs[0]).To(HavePrefix("testurl.com/forecast?latitude=43.0&longitude=23.0&timezone=auto&daily=temperature_2m_max,"))
+				Expect(requestedURLs[0]).To(HaveSuffix(",sunshine_duration"))
+				Expect(strings.Count(requestedURLs[0], "daily=")).To(Equal(1))
+				Expect(requestedURLs[1]).To(Equal("testurl.com/forecast?latitude=43.0&longitude=23.0&timezone=auto&hourly=temperature_2m,precipitation_probability,precipitation,wind_speed_10m,cloud_cover"))
+			})
+		})
+	})
+
+	Context("PastDays", func() {
+		When("past days are configured", func() {
+			var requestedURLs []string
+			BeforeEach(func() {
+				omc.PastDays = 5
+				mockHTTPClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					requestedURLs = append(requestedURLs, req.URL.String())
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(bytes.NewBufferString("{}")),
+					}, nil
+				}).Times(2)
+			})
+
+			It("should ask for them with daily forecasts only", func() {
+				_, err := omc.GetForecast("43.0", "23.0")
+				Expect(err).ToNot(HaveOccurred())
+				_, err = omc.GetHourlyForecast("43.0", "23.0")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(requestedURLs[0]).To(HaveSuffix("&past_days=5"))
+				Expect(requestedURLs[1]).ToNot(ContainSubstring("past_days"))
+			})
+		})
+	})
+
+	Context("GetForecasts", func() {
+		When("several locations are requested", func() {
+			var requestedURL string
+			BeforeEach(func() {
+				response := "[{\"latitude\":43.0,\"longitude\":23.0,\"daily\":{\"time\":[\"2025-07-10\"],\"temperature_2m_max\":[20.8],\"uv_index_max\":[5.3],\"precipitation_probability_max\":[0]}}," +
+					"{\"latitude\":44.0,\"longitude\":24.0,\"daily\":{\"time\":[\"2025-07-10\"],\"temperature_2m_max\":[25.1],\"uv_index_max\":[6.1],\"precipitation_probability_max\":[10]}}]"
+				mockHTTPClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					requestedURL = req.URL.String()
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(bytes.NewBufferString(response)),
+					}, nil
+				}).Times(1)
+			})
+
+			It("should return one forecast per location in a single call", func() {
+				resp, err := omc.GetForecasts([]handler.Location{{Lat: "43.0", Lon: "23.0"}, {Lat: "44.0", Lon: "24.0"}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(requestedURL).To(Equal("testurl.com/latitude=43.0,44.0&longitude=23.0,24.0&daily=temperature_2m_max,uv_index_max,precipitation_probability_max,weather_code,sunrise,sunset,daylight_duration,temperature_2m_min,apparent_temperature_max,apparent_temperature_min,precipitation_sum,rain_sum,snowfall_sum,precipitation_hours,wind_speed_10m_max,wind_gusts_10m_max,wind_direction_10m_dominant"))
+				Expect(resp).To(HaveLen(2))
+				Expect(resp[0]["2025-07-10"].Temp2max).To(Equal(20.8))
+				Expect(resp[1]["2025-07-10"].Latitude).To(Equal("44.0000"))
+				Expect(resp[1]["2025-07-10"].Temp2max).To(Equal(25.1))
+			})
+		})
+
+		When("a single location is requested", func() {
+			BeforeEach(func() {
+				response := "{\"latitude\":43.0,\"longitude\":23.0,\"daily\":{\"time\":[\"2025-07-10\"],\"temperature_2m_max\":[20.8],\"uv_index_max\":[5.3],\"precipitation_probability_max\":[0]}}"
+				mockHTTPClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewBufferString(response)),
+				}, nil).Times(1)
+			})
+
+			It("should decode the object response", func() {
+				resp, err := omc.GetForecasts([]handler.Location{{Lat: "43.0", Lon: "23.0"}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(resp).To(HaveLen(1))
+				Expect(*resp[0]["2025-07-10"].UvIndexMax).To(Equal(5.3))
+			})
+		})
+
+		When("more locations than fit in one call are requested", func() {
+			BeforeEach(func() {
+				mockHTTPClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					count := len(strings.Split(strings.SplitN(strings.SplitN(req.URL.String(), "longitude=", 2)[1], "&", 2)[0], ","))
+					items := make([]string, count)
+					for i := range items {
+						items[i] = "{\"latitude\":43.0,\"longitude\":23.0,\"daily\":{\"time\":[\"2025-07-10\"],\"temperature_2m_max\":[20.8],\"uv_index_max\":[5.3],\"precipitation_probability_max\":[0]}}"
+					}
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(bytes.NewBufferString("[" + strings.Join(items, ",") + "]")),
+					}, nil
+				}).Times(2)
+			})
+
+			It("should split them into chunks", func() {
+				locations := make([]handler.Location, 60)
+				for i := range locations {
+					locations[i] = handler.Location{Lat: "43.0", Lon: "23.0"}
+				}
+				resp, err := omc.GetForecasts(locations)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(resp).To(HaveLen(60))
+			})
+		})
+
+		When("fewer forecasts than locations are returned", func() {
+			BeforeEach(func() {
+				response := "[{\"latitude\":43.0,\"longitude\":23.0,\"daily\":{\"time\":[]}}]"
+				mockHTTPClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewBufferString(response)),
+				}, nil).Times(1)
+			})
+
+			It("should return error", func() {
+				resp, err := omc.GetForecasts([]handler.Location{{Lat: "43.0", Lon: "23.0"}, {Lat: "44.0", Lon: "24.0"}})
+				Expect(err).To(HaveOccurred())
+				Expect(resp).To(BeNil())
+			})
+		})
+	})
+
+	Context("GetHourly", func() {
+		When("everything works", func() {
+			var requestedURL string
+			BeforeEach(func() {
+				response := "{\"latitude\":43.0,\"longitude\":23.0,\"hourly\":{\"time\":[\"2025-07-10T00:00\",\"2025-07-10T01:00\",\"2025-07-11T00:00\"],\"temperature_2m\":[18.5,17.9,19.1],\"precipitation_probability\":[0,5,10],\"precipitation\":[0,0.1,0.4],\"wind_speed_10m\":[4.2,3.8,6.0],\"cloud_cover\":[20,25,90]}}"
+				mockHTTPClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+					requestedURL = req.URL.String()
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(bytes.NewBufferString(response)),
+					}, nil
+				}).Times(1)
+			})
+
+			It("should return hourly weather grouped by date", func() {
+				resp, err := omc.GetHourlyForecast("43.0", "23.0")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(requestedURL).To(Equal("testurl.com/hourly?latitude=43.0&longitude=23.0&hourly=temperature_2m,precipitation_probability,precipitation,wind_speed_10m,cloud_cover"))
+				Expect(len(resp)).To(Equal(2))
+				Expect(resp["2025-07-10"]).To(HaveLen(2))
+				Expect(resp["2025-07-10"][1].Time).To(Equal("2025-07-10T01:00"))
+				Expect(resp["2025-07-10"][1].Temp2m).To(Equal(17.9))
+				Expect(resp["2025-07-10"][1].Precipitation).To(Equal(0.1))
+				Expect(resp["2025-07-11"][0].CloudCover).To(Equal(float64(90)))
+				Expect(resp["2025-07-11"][0].Latitude).To(Equal("43.0000"))
+			})
+		})
+
+		When("a variable is missing", func() {
+			BeforeEach(func() {
+				response := "{\"latitude\":43.0,\"longitude\":23.0,\"hourly\":{\"time\":[\"2025-07-10T00:00\",\"2025-07-10T01:00\"],\"temperature_2m\":[18.5,17.9],\"precipitation_probability\":[0,5],\"precipitation\":[0,0.1],\"wind_speed_10m\":[4.2,3.8]}}"
+				mockHTTPClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewBufferString(response)),
+				}, nil).Times(1)
+			})
+
+			It("should return error", func() {
+				resp, err := omc.GetHourlyForecast("43.0", "23.0")
+				Expect(err).To(MatchError("expected 2 hourly cloud_cover values from OpenMateo, got 0"))
+				Expect(resp).To(BeNil())
+			})
+		})
+
+		When("request fails", func() {
+			BeforeEach(func() {
+				mockHTTPClient.EXPECT().Do(gomock.Any()).Return(&http.Response{}, errors.New("error")).Times(1)
+			})
+
+			It("should return error", func() {
+				resp, err := omc.GetHourlyForecast("43.0", "23.0")
+				Expect(err).To(HaveOccurred())
+				Expect(resp).To(BeNil())
+			})
+		})
+	})
+
 }))
@@ -0,0 +1,35 @@
+// Package wind describes wind directions as compass points and wind speeds
+// on the Beaufort scale.
+package wind
+
+import "math"
+
+// compassPoints are the 16 points of the compass, clockwise from north.
+var compassPoints = [16]string{
+	"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE",
+	"S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW",
+}
+
+// Compass returns the compass point closest to a direction in degrees, the
+// direction the wind blows from.
+func Compass(degrees float64) string {
+	degrees = math.Mod(degrees, 360)
+	if degrees < 0 {
+		degrees += 360
+	}
+	return compassPoints[int(math.Round(degrees/22.5))%len(compassPoints)]
+}
+
+// beaufortLimits are the upper limits in km/h of Beaufort forces 0 to 11,
+// anything faster is a hurricane, force 12.
+var beaufortLimits = [12]float64{1, 6, 12, 20, 29, 39, 50, 62, 75, 89, 103, 118}
+
+// Beaufort returns the Beaufort force of a wind speed in km/h.
+func Beaufort(kmh float64) int {
+	for force, limit := range beaufortLimits {
+		if kmh < limit {
+			return force
+		}
+	}
+	return len(beaufortLimits)
+}
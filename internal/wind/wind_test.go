@@ -0,0 +1,37 @@
+package wind_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"weather-service/internal/wind"
+)
+
+var _ = Describe("Wind", func() {
+	DescribeTable("Compass",
+		func(degrees float64, expected string) {
+			Expect(wind.Compass(degrees)).To(Equal(expected))
+		},
+		Entry("north", 0.0, "N"),
+		Entry("just west of north", 354.0, "N"),
+		Entry("north-north-east", 22.5, "NNE"),
+		Entry("halfway rounds up", 11.25, "NNE"),
+		Entry("east", 90.0, "E"),
+		Entry("south-west", 225.0, "SW"),
+		Entry("west-north-west", 290.0, "WNW"),
+		Entry("full circle", 360.0, "N"),
+		Entry("negative", -90.0, "W"),
+	)
+
+	DescribeTable("Beaufort",
+		func(kmh float64, expected int) {
+			Expect(wind.Beaufort(kmh)).To(Equal(expected))
+		},
+		Entry("calm", 0.5, 0),
+		Entry("light air", 1.0, 1),
+		Entry("gentle breeze", 15.0, 3),
+		Entry("fresh breeze", 38.9, 5),
+		Entry("gale", 70.0, 8),
+		Entry("violent storm", 117.9, 11),
+		Entry("hurricane", 118.0, 12),
+	)
+})
@@ -0,0 +1,82 @@
+// Package wmo describes the WMO 4677 weather interpretation codes that
+// Open-Meteo reports as weather_code, in several languages.
+package wmo
+
+// Severity classifies how disruptive a weather condition is.
+type Severity string
+
+const (
+	SeverityNone     Severity = "none"
+	SeverityMinor    Severity = "minor"
+	SeverityModerate Severity = "moderate"
+	SeveritySevere   Severity = "severe"
+)
+
+// DefaultLanguage is used for descriptions in languages without a translation.
+const DefaultLanguage = "en"
+
+// Code describes a WMO weather interpretation code.
+type Code struct {
+	Code     int
+	Severity Severity
+	// IconDay and IconNight are icon ids, equal for conditions that look
+	// the same by day and by night.
+	IconDay   string
+	IconNight string
+	// Descriptions is keyed by language.
+	Descriptions map[string]string
+}
+
+// codes is the WMO 4677 subset Open-Meteo reports.
+var codes = map[int]Code{
+	0:  {Code: 0, Severity: SeverityNone, IconDay: "clear-day", IconNight: "clear-night", Descriptions: map[string]string{"en": "Clear sky", "bg": "Ясно"}},
+	1:  {Code: 1, Severity: SeverityNone, IconDay: "mostly-clear-day", IconNight: "mostly-clear-night", Descriptions: map[string]string{"en": "Mainly clear", "bg": "Предимно ясно"}},
+	2:  {Code: 2, Severity: SeverityNone, IconDay: "partly-cloudy-day", IconNight: "partly-cloudy-night", Descriptions: map[string]string{"en": "Partly cloudy", "bg": "Частична облачност"}},
+	3:  {Code: 3, Severity: SeverityNone, IconDay: "overcast", IconNight: "overcast", Descriptions: map[string]string{"en": "Overcast", "bg": "Облачно"}},
+	45: {Code: 45, Severity: SeverityMinor, IconDay: "fog-day", IconNight: "fog-night", Descriptions: map[string]string{"en": "Fog", "bg": "Мъгла"}},
+	48: {Code: 48, Severity: SeverityModerate, IconDay: "fog-day", IconNight: "fog-night", Descriptions: map[string]string{"en": "Depositing rime fog", "bg": "Мъгла със скреж"}},
+	51: {Code: 51, Severity: SeverityMinor, IconDay: "drizzle", IconNight: "drizzle", Descriptions: map[string]string{"en": "Light drizzle", "bg": "Слаб ръмеж"}},
+	53: {Code: 53, Severity: SeverityMinor, IconDay: "drizzle", IconNight: "drizzle", Descriptions: map[string]string{"en": "Moderate drizzle", "bg": "Умерен ръмеж"}},
+	55: {Code: 55, Severity: SeverityMinor, IconDay: "drizzle", IconNight: "drizzle", Descriptions: map[string]string{"en": "Dense drizzle", "bg": "Силен ръмеж"}},
+	56: {Code: 56, Severity: SeverityModerate, IconDay: "freezing-drizzle", IconNight: "freezing-drizzle", Descriptions: map[string]string{"en": "Light freezing drizzle", "bg": "Слаб леден ръмеж"}},
+	57: {Code: 57, Severity: SeveritySevere, IconDay: "freezing-drizzle", IconNight: "freezing-drizzle", Descriptions: map[string]string{"en": "Dense freezing drizzle", "bg": "Силен леден ръмеж"}},
+	61: {Code: 61, Severity: SeverityMinor, IconDay: "rain", IconNight: "rain", Descriptions: map[string]string{"en": "Slight rain", "bg": "Слаб дъжд"}},
+	63: {Code: 63, Severity: SeverityModerate, IconDay: "rain", IconNight: "rain", Descriptions: map[string]string{"en": "Moderate rain", "bg": "Умерен дъжд"}},
+	65: {Code: 65, Severity: SeveritySevere, IconDay: "heavy-rain", IconNight: "heavy-rain", Descriptions: map[string]string{"en": "Heavy rain", "bg": "Силен дъжд"}},
+	66: {Code: 66, Severity: SeverityModerate, IconDay: "freezing-rain", IconNight: "freezing-rain", Descriptions: map[string]string{"en": "Light freezing rain", "bg": "Слаб леден дъжд"}},
+	67: {Code: 67, Severity: SeveritySevere, IconDay: "freezing-rain", IconNight: "freezing-rain", Descriptions: map[string]string{"en": "Heavy freezing rain", "bg": "Силен леден дъжд"}},
+	71: {Code: 71, Severity: SeverityMinor, IconDay: "snow", IconNight: "snow", Descriptions: map[string]string{"en": "Slight snow fall", "bg": "Слаб снеговалеж"}},
+	73: {Code: 73, Severity: SeverityModerate, IconDay: "snow", IconNight: "snow", Descriptions: map[string]string{"en": "Moderate snow fall", "bg": "Умерен снеговалеж"}},
+	75: {Code: 75, Severity: SeveritySevere, IconDay: "heavy-snow", IconNight: "heavy-snow", Descriptions: map[string]string{"en": "Heavy snow fall", "bg": "Силен снеговалеж"}},
+	77: {Code: 77, Severity: SeverityMinor, IconDay: "snow-grains", IconNight: "snow-grains", Descriptions: map[string]string{"en": "Snow grains", "bg": "Снежни зърна"}},
+	80: {Code: 80, Severity: SeverityMinor, IconDay: "showers-day", IconNight: "showers-night", Descriptions: map[string]string{"en": "Slight rain showers", "bg": "Слаби превалявания"}},
+	81: {Code: 81, Severity: SeverityModerate, IconDay: "showers-day", IconNight: "showers-night", Descriptions: map[string]string{"en": "Moderate rain showers", "bg": "Умерени превалявания"}},
+	82: {Code: 82, Severity: SeveritySevere, IconDay: "heavy-showers-day", IconNight: "heavy-showers-night", Descriptions: map[string]string{"en": "Violent rain showers", "bg": "Проливни превалявания"}},
+	85: {Code: 85, Severity: SeverityModerate, IconDay: "snow-showers-day", IconNight: "snow-showers-night", Descriptions: map[string]string{"en": "Slight snow showers", "bg": "Слаби снежни превалявания"}},
+	86: {Code: 86, Severity: SeveritySevere, IconDay: "snow-showers-day", IconNight: "snow-showers-night", Descriptions: map[string]string{"en": "Heavy snow showers", "bg": "Силни снежни превалявания"}},
+	95: {Code: 95, Severity: SeveritySevere, IconDay: "thunderstorm", IconNight: "thunderstorm", Descriptions: map[string]string{"en": "Thunderstorm", "bg": "Гръмотевична буря"}},
+	96: {Code: 96, Severity: SeveritySevere, IconDay: "thunderstorm-hail", IconNight: "thunderstorm-hail", Descriptions: map[string]string{"en": "Thunderstorm with slight hail", "bg": "Гръмотевична буря със слаба градушка"}},
+	99: {Code: 99, Severity: SeveritySevere, IconDay: "thunderstorm-hail", IconNight: "thunderstorm-hail", Descriptions: map[string]string{"en": "Thunderstorm with heavy hail", "bg": "Гръмотевична буря със силна градушка"}},
+}
+
+// Lookup returns the description of a WMO weather code.
+func Lookup(code int) (Code, bool) {
+	c, ok := codes[code]
+	return c, ok
+}
+
+// Description returns the description in lang, falling back to DefaultLanguage.
+func (c Code) Description(lang string) string {
+	if d, ok := c.Descriptions[lang]; ok {
+		return d
+	}
+	return c.Descriptions[DefaultLanguage]
+}
+
+// Icon returns the day or night icon id.
+func (c Code) Icon(night bool) string {
+	if night {
+		return c.IconNight
+	}
+	return c.IconDay
+}
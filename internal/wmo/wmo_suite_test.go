@@ -0,0 +1,13 @@
+package wmo_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestWMO(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "WMO Suite")
+}
@@ -0,0 +1,33 @@
+package wmo_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"weather-service/internal/wmo"
+)
+
+var _ = Describe("Code", func() {
+	It("should describe known codes", func() {
+		code, ok := wmo.Lookup(61)
+		Expect(ok).To(BeTrue())
+		Expect(code.Severity).To(Equal(wmo.SeverityMinor))
+		Expect(code.Description("en")).To(Equal("Slight rain"))
+		Expect(code.Description("bg")).To(Equal("Слаб дъжд"))
+	})
+
+	It("should fall back to the default language", func() {
+		code, _ := wmo.Lookup(0)
+		Expect(code.Description("fr")).To(Equal("Clear sky"))
+	})
+
+	It("should have day and night icons", func() {
+		code, _ := wmo.Lookup(2)
+		Expect(code.Icon(false)).To(Equal("partly-cloudy-day"))
+		Expect(code.Icon(true)).To(Equal("partly-cloudy-night"))
+	})
+
+	It("should not know other codes", func() {
+		_, ok := wmo.Lookup(42)
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -34,7 +34,9 @@ resource "aws_lambda_function" "weather_lambda" {
     variables = {
       DYNAMODB_TABLE = var.dynamo_table_name
       TTL_MINUTES = 10
-      OPEN_MATEO_URL= "https://api.open-meteo.com/v1/forecast?latitude=%s&longitude=%s&daily=temperature_2m_max,uv_index_max,precipitation_probability_max&timezone=auto"
+      OPEN_MATEO_URL= "https://api.open-meteo.com/v1/forecast?latitude=%s&longitude=%s&timezone=auto"
+      OPEN_MATEO_HOURLY_URL= "https://api.open-meteo.com/v1/forecast?latitude=%s&longitude=%s&timezone=auto"
+      OPEN_MATEO_ARCHIVE_URL= "https://archive-api.open-meteo.com/v1/archive?latitude=%s&longitude=%s&start_date=%s&end_date=%s&timezone=auto"
     }
   }
 }
@@ -58,6 +60,30 @@ resource "aws_apigatewayv2_route" "weather_route" {
   target    = "integrations/${aws_apigatewayv2_integration.lambda_integration.id}"
 }
 
+resource "aws_apigatewayv2_route" "weather_hourly_route" {
+  api_id    = aws_apigatewayv2_api.weather_api.id
+  route_key = "GET /weather/hourly"
+  target    = "integrations/${aws_apigatewayv2_integration.lambda_integration.id}"
+}
+
+resource "aws_apigatewayv2_route" "weather_batch_route" {
+  api_id    = aws_apigatewayv2_api.weather_api.id
+  route_key = "POST /weather/batch"
+  target    = "integrations/${aws_apigatewayv2_integration.lambda_integration.id}"
+}
+
+resource "aws_apigatewayv2_route" "weather_calendar_route" {
+  api_id    = aws_apigatewayv2_api.weather_api.id
+  route_key = "GET /weather/calendar"
+  target    = "integrations/${aws_apigatewayv2_integration.lambda_integration.id}"
+}
+
+resource "aws_apigatewayv2_route" "weather_chart_route" {
+  api_id    = aws_apigatewayv2_api.weather_api.id
+  route_key = "GET /weather/chart.svg"
+  target    = "integrations/${aws_apigatewayv2_integration.lambda_integration.id}"
+}
+
 resource "aws_apigatewayv2_stage" "default_stage" {
   api_id      = aws_apigatewayv2_api.weather_api.id
   name        = "$default"